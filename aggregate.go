@@ -0,0 +1,176 @@
+package tiles
+
+import (
+	"fmt"
+	"math"
+)
+
+// Aggregator summarizes the values of a tile as a monoid.
+// Combine must be associative and commutative with Identity as its identity,
+// since summaries are combined in whatever order the values were added & their tiles are stored.
+type Aggregator interface {
+	// Identity returns the summary of no values
+	Identity() interface{}
+	// Lift returns the summary of a single value
+	Lift(v interface{}) interface{}
+	// Combine merges two summaries
+	Combine(a, b interface{}) interface{}
+}
+
+// AggregateIndex is a QuadtreeIndex that keeps a summary of the values under every node of the tree,
+// so Aggregate returns the summary of any tile without visiting its values.
+// Adds update the summaries along the path to the root, Remove, Delete & Replace recompute the summaries of the tiles they change.
+// Use NewAggregateIndex to choose the aggregator, the zero value is an empty index that counts its values like CountAggregator.
+// AggregateIndex is thread safe
+type AggregateIndex struct {
+	QuadtreeIndex
+}
+
+// NewAggregateIndex returns an empty AggregateIndex that summarizes values with the aggregator, nil counts them
+func NewAggregateIndex(agg Aggregator) *AggregateIndex {
+	if agg == nil {
+		return &AggregateIndex{}
+	}
+	idx := &AggregateIndex{QuadtreeIndex{agg: agg}}
+	idx.root.fold(agg)
+	idx.root.refresh(agg)
+	return idx
+}
+
+// Aggregate returns the summary of the values aggregated under the tile, which is Identity if it doesn't have any.
+// It walks at most t.Z nodes regardless of how many values are in the index.
+func (idx *AggregateIndex) Aggregate(t Tile) interface{} {
+	idx.RLock()
+	defer idx.RUnlock()
+	path := idx.path(t, false)
+	if idx.agg == nil {
+		// without an aggregator the nodes only keep their counts
+		if path == nil {
+			return 0
+		}
+		return path[len(path)-1].count
+	}
+	if path == nil {
+		return idx.agg.Identity()
+	}
+	return path[len(path)-1].summary
+}
+
+// NewAggregator returns a custom Aggregator from its functions
+func NewAggregator(identity interface{}, lift func(v interface{}) interface{}, combine func(a, b interface{}) interface{}) Aggregator {
+	return funcAggregator{identity: identity, lift: lift, combine: combine}
+}
+
+type funcAggregator struct {
+	identity interface{}
+	lift     func(v interface{}) interface{}
+	combine  func(a, b interface{}) interface{}
+}
+
+func (a funcAggregator) Identity() interface{}                { return a.identity }
+func (a funcAggregator) Lift(v interface{}) interface{}       { return a.lift(v) }
+func (a funcAggregator) Combine(x, y interface{}) interface{} { return a.combine(x, y) }
+
+// CountAggregator counts the values as an int
+func CountAggregator() Aggregator {
+	return NewAggregator(0,
+		func(interface{}) interface{} { return 1 },
+		func(a, b interface{}) interface{} { return a.(int) + b.(int) },
+	)
+}
+
+// SumAggregator sums the values as a float64.
+// Values are converted with number, or if it's nil, values must be one of Go's int, uint or float types.
+func SumAggregator(number func(v interface{}) float64) Aggregator {
+	number = numberOrDefault(number)
+	return NewAggregator(0.0,
+		func(v interface{}) interface{} { return number(v) },
+		func(a, b interface{}) interface{} { return a.(float64) + b.(float64) },
+	)
+}
+
+// MinAggregator returns the smallest value as a float64, which is +Inf for no values.
+// Values are converted like SumAggregator.
+func MinAggregator(number func(v interface{}) float64) Aggregator {
+	number = numberOrDefault(number)
+	return NewAggregator(math.Inf(1),
+		func(v interface{}) interface{} { return number(v) },
+		func(a, b interface{}) interface{} { return math.Min(a.(float64), b.(float64)) },
+	)
+}
+
+// MaxAggregator returns the largest value as a float64, which is -Inf for no values.
+// Values are converted like SumAggregator.
+func MaxAggregator(number func(v interface{}) float64) Aggregator {
+	number = numberOrDefault(number)
+	return NewAggregator(math.Inf(-1),
+		func(v interface{}) interface{} { return number(v) },
+		func(a, b interface{}) interface{} { return math.Max(a.(float64), b.(float64)) },
+	)
+}
+
+// MeanSummary is the summary of MeanAggregator
+type MeanSummary struct {
+	Sum   float64
+	Count int
+}
+
+// Mean returns the mean of the values or NaN if there are none
+func (m MeanSummary) Mean() float64 {
+	if m.Count == 0 {
+		return math.NaN()
+	}
+	return m.Sum / float64(m.Count)
+}
+
+// MeanAggregator keeps a MeanSummary of the values.
+// Values are converted like SumAggregator.
+func MeanAggregator(number func(v interface{}) float64) Aggregator {
+	number = numberOrDefault(number)
+	return NewAggregator(MeanSummary{},
+		func(v interface{}) interface{} { return MeanSummary{Sum: number(v), Count: 1} },
+		func(a, b interface{}) interface{} {
+			x, y := a.(MeanSummary), b.(MeanSummary)
+			return MeanSummary{Sum: x.Sum + y.Sum, Count: x.Count + y.Count}
+		},
+	)
+}
+
+func numberOrDefault(number func(v interface{}) float64) func(v interface{}) float64 {
+	if number == nil {
+		return toFloat
+	}
+	return number
+}
+
+// toFloat converts Go's numeric types to a float64, it panics for anything else
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	case int:
+		return float64(n)
+	case int8:
+		return float64(n)
+	case int16:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint:
+		return float64(n)
+	case uint8:
+		return float64(n)
+	case uint16:
+		return float64(n)
+	case uint32:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	}
+	check(fmt.Errorf("Invalid value %v: %T is not a number", v, v))
+	return 0
+}
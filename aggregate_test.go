@@ -0,0 +1,151 @@
+package tiles
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestAggregateIndex(t *testing.T) {
+	longest := NewAggregator("",
+		func(v interface{}) interface{} { return v.(string) },
+		func(a, b interface{}) interface{} {
+			x, y := a.(string), b.(string)
+			if len(y) > len(x) || (len(y) == len(x) && y > x) {
+				return y
+			}
+			return x
+		},
+	)
+	numeric := func(v interface{}) float64 { return float64(len(v.(string))) }
+	aggTests := []struct {
+		name string
+		agg  Aggregator
+		// idx defaults to NewAggregateIndex(agg)
+		idx *AggregateIndex
+	}{
+		{"Count", CountAggregator(), nil},
+		{"Sum", SumAggregator(numeric), nil},
+		{"Min", MinAggregator(numeric), nil},
+		{"Max", MaxAggregator(numeric), nil},
+		{"Mean", MeanAggregator(numeric), nil},
+		{"Longest", longest, nil},
+		// without an aggregator the index counts
+		{"ZeroIndexCount", CountAggregator(), &AggregateIndex{}},
+		{"NilCount", CountAggregator(), NewAggregateIndex(nil)},
+	}
+	esb := FromCoordinate(40.7484, -73.9857, 18)
+	nyc := Tile{X: 75, Y: 96, Z: 8}
+	den := Tile{X: 106, Y: 194, Z: 9}
+	z12 := esb.Quadkey().Ancestor(12).ToTile()
+	queries := []Tile{{}, nyc, den, esb, z12, {X: 1, Y: 1, Z: 1}}
+	errf := "%sAggregator %s Aggregate(%v) -> %v not %v"
+	for _, test := range aggTests {
+		idx := test.idx
+		if idx == nil {
+			idx = NewAggregateIndex(test.agg)
+		}
+		check := func(step string) {
+			for _, tile := range queries {
+				// reduce the raw values to check the precomputed summary
+				want := test.agg.Identity()
+				for _, v := range idx.Values(tile) {
+					want = test.agg.Combine(want, test.agg.Lift(v))
+				}
+				if got := idx.Aggregate(tile); !summaryEquals(got, want) {
+					t.Errorf(errf, test.name, step, tile, got, want)
+				}
+			}
+		}
+		check("empty")
+		hydrateIndex(idx)
+		idx.Add(esb, "EmpireStateBuilding", "Bus")
+		idx.Add(FromCoordinate(51.5007, -0.1246, 18), "BigBen")
+		check("Add")
+		idx.Remove(nyc, func(v interface{}) bool { return len(v.(string)) < 19 })
+		check("Remove")
+		idx.Replace(esb, "Ferry", "Bus")
+		check("Replace")
+		idx.Delete(z12, true)
+		check("Delete descendants")
+		idx.Delete(esb, false)
+		check("Delete")
+	}
+}
+
+func TestAggregators(t *testing.T) {
+	vals := []interface{}{3, int8(-2), uint16(7), float32(0.5), 1.5}
+	aggTests := []struct {
+		name string
+		agg  Aggregator
+		want interface{}
+	}{
+		{"Count", CountAggregator(), 5},
+		{"Sum", SumAggregator(nil), 10.0},
+		{"Min", MinAggregator(nil), -2.0},
+		{"Max", MaxAggregator(nil), 7.0},
+		{"Mean", MeanAggregator(nil), MeanSummary{Sum: 10, Count: 5}},
+	}
+	errf := "%sAggregator over %v -> %v not %v"
+	for _, test := range aggTests {
+		idx := NewAggregateIndex(test.agg)
+		for _, v := range vals {
+			idx.Add(Tile{X: rand.Intn(4), Y: rand.Intn(4), Z: 2}, v)
+		}
+		if got := idx.Aggregate(Tile{}); !summaryEquals(got, test.want) {
+			t.Errorf(errf, test.name, vals, got, test.want)
+		}
+	}
+	if mean := (MeanSummary{Sum: 10, Count: 5}).Mean(); mean != 2 {
+		t.Errorf("MeanSummary.Mean -> %v", mean)
+	}
+	if mean := (MeanSummary{}).Mean(); !math.IsNaN(mean) {
+		t.Errorf("MeanSummary.Mean of no values -> %v", mean)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("SumAggregator(nil) should panic on values that aren't numbers")
+		}
+	}()
+	SumAggregator(nil).Lift("not a number")
+}
+
+// summaryEquals compares summaries avoiding float precision from combining in different orders
+func summaryEquals(a, b interface{}) bool {
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		return ok && (x == y || floatEquals(x, y))
+	case MeanSummary:
+		y, ok := b.(MeanSummary)
+		return ok && x.Count == y.Count && floatEquals(x.Sum, y.Sum)
+	}
+	return a == b
+}
+
+func TestAggregateIndexPanic(t *testing.T) {
+	idx := NewAggregateIndex(SumAggregator(nil))
+	tile := Tile{X: 1, Y: 2, Z: 3}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("SumAggregator(nil) should panic on values that aren't numbers")
+			}
+		}()
+		idx.Add(tile, 1.0, "not a number")
+	}()
+	// the panic happens before the tree is touched, so none of the values are added
+	if vals := idx.Values(Tile{}); len(vals) != 0 {
+		t.Errorf("AggregateIndex.Values after a panic -> %v", vals)
+	}
+	if sum := idx.Aggregate(Tile{}); sum != 0.0 {
+		t.Errorf("AggregateIndex.Aggregate after a panic -> %v", sum)
+	}
+	for tile := range idx.TileRange(0, ZMax) {
+		t.Errorf("AggregateIndex.TileRange after a panic -> %v", tile)
+	}
+	idx.Add(tile, 2.0)
+	if sum := idx.Aggregate(Tile{}); sum != 2.0 {
+		t.Errorf("AggregateIndex.Aggregate after a panic & Add -> %v", sum)
+	}
+}
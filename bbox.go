@@ -0,0 +1,227 @@
+package tiles
+
+import (
+	"fmt"
+	"math"
+)
+
+// BBox is a WGS84 bounding box in degrees.
+// If West > East the box crosses the antimeridian.
+type BBox struct {
+	North, South, East, West float64
+}
+
+// NW returns the north west corner of the box
+func (b BBox) NW() Coordinate {
+	return Coordinate{Lat: b.North, Lon: b.West}
+}
+
+// NE returns the north east corner of the box
+func (b BBox) NE() Coordinate {
+	return Coordinate{Lat: b.North, Lon: b.East}
+}
+
+// SW returns the south west corner of the box
+func (b BBox) SW() Coordinate {
+	return Coordinate{Lat: b.South, Lon: b.West}
+}
+
+// SE returns the south east corner of the box
+func (b BBox) SE() Coordinate {
+	return Coordinate{Lat: b.South, Lon: b.East}
+}
+
+// Equals checks if these boxes are equal avoiding some float precision
+func (b BBox) Equals(that BBox) bool {
+	return b.NW().Equals(that.NW()) && b.SE().Equals(that.SE())
+}
+
+func (b BBox) String() string {
+	return fmt.Sprintf("[N: %v, S: %v, E: %v, W: %v]", b.North, b.South, b.East, b.West)
+}
+
+// CrossesAntimeridian returns true if the box wraps across 180° (West > East)
+func (b BBox) CrossesAntimeridian() bool {
+	return b.West > b.East
+}
+
+// Contains returns true if the coordinate is inside of or on the edge of the box
+func (b BBox) Contains(c Coordinate) bool {
+	return c.Lat >= b.South && c.Lat <= b.North && b.containsLon(wrapLon(c.Lon))
+}
+
+// Intersects returns true if the boxes overlap, including touching edges
+func (b BBox) Intersects(that BBox) bool {
+	if b.South > that.North || that.South > b.North {
+		return false
+	}
+	for _, x := range b.lonSpans() {
+		for _, y := range that.lonSpans() {
+			if x[0] <= y[1] && y[0] <= x[1] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Union returns the smallest box that contains both boxes, which may cross the antimeridian
+func (b BBox) Union(that BBox) BBox {
+	u := BBox{
+		North: math.Max(b.North, that.North),
+		South: math.Min(b.South, that.South),
+		West:  MinLon,
+		East:  MaxLon,
+	}
+	width := 360.0
+	for _, c := range [][2]float64{
+		{b.West, b.East},
+		{b.West, that.East},
+		{that.West, b.East},
+		{that.West, that.East},
+	} {
+		arc := BBox{West: c[0], East: c[1]}
+		if w := arc.width(); w < width && arc.containsArc(b) && arc.containsArc(that) {
+			u.West, u.East, width = c[0], c[1], w
+		}
+	}
+	return u
+}
+
+// Expand returns the box grown by meters on every side.
+// Latitudes are clipped to the poles and boxes that grow past the full width of the map span all longitudes.
+func (b BBox) Expand(meters float64) BBox {
+	dlat := meters / EarthRadiusM * 180 / math.Pi
+	e := BBox{
+		North: math.Min(b.North+dlat, 90),
+		South: math.Max(b.South-dlat, -90),
+		West:  MinLon,
+		East:  MaxLon,
+	}
+	// longitude degrees are shortest at the latitude furthest from the equator
+	lat := math.Max(math.Abs(e.North), math.Abs(e.South))
+	cos := math.Cos(lat * math.Pi / 180)
+	if cos < 1e-12 {
+		return e
+	}
+	dlon := dlat / cos
+	if b.width()+2*dlon >= 360 {
+		return e
+	}
+	e.West = wrapLon(b.West - dlon)
+	e.East = wrapLon(b.East + dlon)
+	return e
+}
+
+// Tiles returns a channel of every tile at the zoom level that intersects the box like TilesInBBox
+func (b BBox) Tiles(zoom int) <-chan Tile {
+	return TilesInBBox(b, zoom)
+}
+
+// TileRange returns a channel of every tile in the zoom range that intersects the box like TilesInBBoxRange
+func (b BBox) TileRange(zmin, zmax int) <-chan Tile {
+	return TilesInBBoxRange(b, zmin, zmax)
+}
+
+// width returns the degrees of longitude spanned by the box
+func (b BBox) width() float64 {
+	if b.CrossesAntimeridian() {
+		return b.East - b.West + 360
+	}
+	return b.East - b.West
+}
+
+// containsLon checks if the lon in [-180, 180] is within the box's longitudes
+func (b BBox) containsLon(lon float64) bool {
+	if b.CrossesAntimeridian() {
+		return lon >= b.West || lon <= b.East
+	}
+	return lon >= b.West && lon <= b.East
+}
+
+// containsArc checks if the longitudes of that are within the longitudes of b
+func (b BBox) containsArc(that BBox) bool {
+	if !b.containsLon(that.West) {
+		return false
+	}
+	offset := that.West - b.West
+	if offset < 0 {
+		offset += 360
+	}
+	return offset+that.width() <= b.width()
+}
+
+// lonSpans splits the box's longitudes into non-wrapping [west, east] spans
+func (b BBox) lonSpans() [][2]float64 {
+	if b.CrossesAntimeridian() {
+		return [][2]float64{{b.West, MaxLon}, {MinLon, b.East}}
+	}
+	return [][2]float64{{b.West, b.East}}
+}
+
+// TilesInBBox returns a channel of every tile at the zoom level that intersects the bbox.
+// Tiles that only touch the east or south edge of the box are not included.
+// If bbox.West > bbox.East, the box is treated as crossing the antimeridian.
+// A zoom outside of [0, ZMax] streams no tiles.
+func TilesInBBox(bbox BBox, zoom int) <-chan Tile {
+	return TilesInBBoxRange(bbox, zoom, zoom)
+}
+
+// TilesInBBoxRange returns a channel of every tile in the zoom range (inclusive) that intersects the bbox.
+// Tiles are streamed by zoom level, then row, then column. The range is clipped to [0, ZMax].
+func TilesInBBoxRange(bbox BBox, zmin, zmax int) <-chan Tile {
+	// clip before starting the producer, a panic in it couldn't be recovered by the caller
+	if zmin < 0 {
+		zmin = 0
+	}
+	if zmax > ZMax {
+		zmax = ZMax
+	}
+	tiles := make(chan Tile, 1<<10)
+	go func() {
+		defer close(tiles)
+		for z := zmin; z <= zmax; z++ {
+			cols, ymin, ymax := bbox.tileRange(z)
+			for y := ymin; y <= ymax; y++ {
+				for _, c := range cols {
+					for x := c[0]; x <= c[1]; x++ {
+						tiles <- Tile{X: x, Y: y, Z: z}
+					}
+				}
+			}
+		}
+	}()
+	return tiles
+}
+
+// tileRange returns the inclusive column spans and row range of the tiles intersecting the box at zoom.
+// A box crossing the antimeridian returns two column spans.
+func (b BBox) tileRange(zoom int) (cols [][2]int, ymin, ymax int) {
+	n := 1 << uint(zoom)
+	west, north := b.NW().tileXY(zoom)
+	east, south := b.SE().tileXY(zoom)
+	ymin, ymax = edgeRange(math.Min(north, south), math.Max(north, south), n)
+	if b.West <= b.East {
+		xmin, xmax := edgeRange(west, east, n)
+		return [][2]int{{xmin, xmax}}, ymin, ymax
+	}
+	wmin, _ := edgeRange(west, float64(n), n)
+	_, emax := edgeRange(0, east, n)
+	if emax >= wmin {
+		return [][2]int{{0, n - 1}}, ymin, ymax
+	}
+	return [][2]int{{0, emax}, {wmin, n - 1}}, ymin, ymax
+}
+
+// edgeRange returns the inclusive tile indexes that intersect the fractional span [lo, hi], clipped to [0, n-1].
+// A hi edge that lands on a tile boundary does not include the next tile.
+func edgeRange(lo, hi float64, n int) (min, max int) {
+	min = int(math.Floor(lo))
+	max = int(math.Ceil(hi)) - 1
+	if max < min {
+		max = min
+	}
+	min = int(clip(float64(min), 0, float64(n-1)))
+	max = int(clip(float64(max), 0, float64(n-1)))
+	return
+}
@@ -0,0 +1,209 @@
+package tiles
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBBoxCorners(t *testing.T) {
+	b := BBox{North: 2, South: -1, East: 4, West: -3}
+	cornerTests := []struct {
+		name   string
+		corner Coordinate
+		coords Coordinate
+	}{
+		{"NW", b.NW(), Coordinate{2, -3}},
+		{"NE", b.NE(), Coordinate{2, 4}},
+		{"SW", b.SW(), Coordinate{-1, -3}},
+		{"SE", b.SE(), Coordinate{-1, 4}},
+	}
+	errf := "BBox%+v.%s() -> %+v"
+	for _, test := range cornerTests {
+		if !test.corner.Equals(test.coords) {
+			t.Errorf(errf, b, test.name, test.corner)
+		}
+	}
+}
+
+func TestTilesInBBox(t *testing.T) {
+	bboxTests := []struct {
+		bbox  BBox
+		zoom  int
+		tiles []Tile
+	}{
+		{BBox{North: 85, South: -85, East: 180, West: -180}, 0, []Tile{{0, 0, 0}}},
+		{BBox{North: 85, South: -85, East: 180, West: -180}, 1, []Tile{{0, 0, 1}, {1, 0, 1}, {0, 1, 1}, {1, 1, 1}}},
+		{BBox{North: 10, South: 0, East: 0, West: -10}, 1, []Tile{{0, 0, 1}}},
+		{BBox{North: 40.7484, South: 40.7484, East: -73.9857, West: -73.9857}, 18, []Tile{FromCoordinate(40.7484, -73.9857, 18)}},
+		{BBox{North: 10, South: -10, East: -170, West: 170}, 3, []Tile{{0, 3, 3}, {7, 3, 3}, {0, 4, 3}, {7, 4, 3}}},
+		{BBox{North: 10, South: 5, East: 10, West: 20}, 1, []Tile{{0, 0, 1}, {1, 0, 1}}},
+	}
+	errf := "TilesInBBox(%v, %d) -> %+v"
+	for _, test := range bboxTests {
+		var tiles []Tile
+		for tile := range TilesInBBox(test.bbox, test.zoom) {
+			tiles = append(tiles, tile)
+		}
+		if !tileSliceEqual(tiles, test.tiles) {
+			t.Errorf(errf, test.bbox, test.zoom, tiles)
+		}
+	}
+}
+
+func TestTilesInBBoxRange(t *testing.T) {
+	bbox := BBox{North: 40.8, South: 40.6, East: -73.9, West: -74.1}
+	c := 0
+	for range TilesInBBoxRange(bbox, 0, 2) {
+		c++
+	}
+	if c != 3 {
+		t.Error("TilesInBBoxRange should generate 3 tiles, got ", c)
+	}
+	// zooms outside of [0, ZMax] are clipped instead of panicking in the producer
+	rangeTests := []struct {
+		zmin, zmax, count int
+	}{
+		{-1, -1, 0},
+		{63, 63, 0},
+		{-5, 1, 2},
+		{ZMax, 100, 1},
+	}
+	for _, test := range rangeTests {
+		c = 0
+		for range TilesInBBoxRange(BBox{North: 1e-6, South: 0, East: 1e-6, West: 0}, test.zmin, test.zmax) {
+			c++
+		}
+		if c != test.count {
+			t.Errorf("TilesInBBoxRange(%d, %d) -> %d tiles not %d", test.zmin, test.zmax, c, test.count)
+		}
+	}
+}
+
+func tileSliceEqual(x, y []Tile) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i, v := range x {
+		if y[i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBBoxContains(t *testing.T) {
+	pacific := BBox{North: 10, South: -10, East: -170, West: 170}
+	bboxTests := []struct {
+		bbox   BBox
+		coords Coordinate
+		in     bool
+	}{
+		{pacific, Coordinate{0, 180}, true},
+		{pacific, Coordinate{0, -175}, true},
+		{pacific, Coordinate{0, 175}, true},
+		{pacific, Coordinate{0, 185}, true},
+		{pacific, Coordinate{0, 0}, false},
+		{pacific, Coordinate{20, 180}, false},
+		{BBox{North: 10, South: -10, East: 10, West: -10}, Coordinate{0, 0}, true},
+		{BBox{North: 10, South: -10, East: 10, West: -10}, Coordinate{0, 180}, false},
+	}
+	errf := "BBox%v.Contains(%v) -> %v"
+	for _, test := range bboxTests {
+		if in := test.bbox.Contains(test.coords); in != test.in {
+			t.Errorf(errf, test.bbox, test.coords, in)
+		}
+	}
+}
+
+func TestBBoxIntersects(t *testing.T) {
+	pacific := BBox{North: 10, South: -10, East: -170, West: 170}
+	bboxTests := []struct {
+		a, b BBox
+		out  bool
+	}{
+		{pacific, BBox{North: 5, South: -5, East: 179, West: 175}, true},
+		{pacific, BBox{North: 5, South: -5, East: -160, West: -175}, true},
+		{pacific, BBox{North: 5, South: -5, East: 10, West: -10}, false},
+		{pacific, BBox{North: 5, South: -5, East: -175, West: 175}, true},
+		{pacific, BBox{North: 30, South: 20, East: -175, West: 175}, false},
+		{BBox{North: 5, South: -5, East: 10, West: -10}, BBox{North: 5, South: -5, East: 20, West: 10}, true},
+	}
+	errf := "BBox%v.Intersects(%v) -> %v"
+	for _, test := range bboxTests {
+		if out := test.a.Intersects(test.b); out != test.out {
+			t.Errorf(errf, test.a, test.b, out)
+		}
+		if out := test.b.Intersects(test.a); out != test.out {
+			t.Errorf(errf, test.b, test.a, out)
+		}
+	}
+}
+
+func TestBBoxUnion(t *testing.T) {
+	bboxTests := []struct {
+		a, b, u BBox
+	}{
+		{
+			BBox{North: 10, South: 0, East: 20, West: 10},
+			BBox{North: 0, South: -10, East: 40, West: 30},
+			BBox{North: 10, South: -10, East: 40, West: 10},
+		},
+		{
+			BBox{North: 10, South: 0, East: 179, West: 170},
+			BBox{North: 10, South: 0, East: -170, West: -179},
+			BBox{North: 10, South: 0, East: -170, West: 170},
+		},
+		{
+			BBox{North: 10, South: 0, East: -170, West: 170},
+			BBox{North: 10, South: 0, East: 175, West: 172},
+			BBox{North: 10, South: 0, East: -170, West: 170},
+		},
+		{
+			BBox{North: 10, South: 0, East: -10, West: 10},
+			BBox{North: 10, South: 0, East: 20, West: -20},
+			BBox{North: 10, South: 0, East: 180, West: -180},
+		},
+	}
+	errf := "BBox%v.Union(%v) -> %v"
+	for _, test := range bboxTests {
+		if u := test.a.Union(test.b); !u.Equals(test.u) {
+			t.Errorf(errf, test.a, test.b, u)
+		}
+		if u := test.b.Union(test.a); !u.Equals(test.u) {
+			t.Errorf(errf, test.b, test.a, u)
+		}
+	}
+}
+
+func TestBBoxExpand(t *testing.T) {
+	// one degree of latitude in meters
+	deg := EarthRadiusM * math.Pi / 180
+	bboxTests := []struct {
+		bbox   BBox
+		meters float64
+		out    BBox
+	}{
+		{BBox{North: 1, South: -1, East: 1, West: -1}, deg, BBox{North: 2, South: -2, East: 2.0006095, West: -2.0006095}},
+		{BBox{North: 1, South: -1, East: 179.5, West: 178}, deg, BBox{North: 2, South: -2, East: -179.4993905, West: 176.9993905}},
+		{BBox{North: 89.5, South: 80, East: 1, West: -1}, deg, BBox{North: 90, South: 79, East: 180, West: -180}},
+		{BBox{North: 1, South: -1, East: 170, West: -170}, 10 * deg, BBox{North: 11, South: -11, East: 180, West: -180}},
+	}
+	errf := "BBox%v.Expand(%v) -> %v"
+	for _, test := range bboxTests {
+		if out := test.bbox.Expand(test.meters); math.Abs(out.North-test.out.North) > 1e-6 || math.Abs(out.South-test.out.South) > 1e-6 ||
+			math.Abs(out.East-test.out.East) > 1e-6 || math.Abs(out.West-test.out.West) > 1e-6 {
+			t.Errorf(errf, test.bbox, test.meters, out)
+		}
+	}
+}
+
+func TestBBoxTiles(t *testing.T) {
+	pacific := BBox{North: 10, South: -10, East: -170, West: 170}
+	var tiles []Tile
+	for tile := range pacific.Tiles(3) {
+		tiles = append(tiles, tile)
+	}
+	if !tileSliceEqual(tiles, []Tile{{0, 3, 3}, {7, 3, 3}, {0, 4, 3}, {7, 4, 3}}) {
+		t.Errorf("BBox%v.Tiles(3) -> %+v", pacific, tiles)
+	}
+}
@@ -0,0 +1,47 @@
+package tiles
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// Codec encodes the values of a TileIndex when they're stored outside of memory
+type Codec interface {
+	Encode(v interface{}) ([]byte, error)
+	Decode(data []byte) (interface{}, error)
+}
+
+// GobCodec encodes values with encoding/gob so they decode to their original types.
+// Types other than the builtin ones need to be registered with gob.Register.
+type GobCodec struct{}
+
+// Encode encodes the value with its gob type name
+func (GobCodec) Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode decodes a value written by Encode
+func (GobCodec) Decode(data []byte) (v interface{}, err error) {
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&v)
+	return
+}
+
+// JSONCodec encodes values with encoding/json.
+// Values decode to the generic JSON types: bool, float64, string, []interface{}, map[string]interface{} and nil.
+type JSONCodec struct{}
+
+// Encode marshals the value to JSON
+func (JSONCodec) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Decode unmarshals the JSON into a generic value
+func (JSONCodec) Decode(data []byte) (v interface{}, err error) {
+	err = json.Unmarshal(data, &v)
+	return
+}
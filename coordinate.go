@@ -19,22 +19,52 @@ func (c Coordinate) Equals(that Coordinate) bool {
 
 // ToPixel gets the Pixel of the coord at the zoom level
 func (c Coordinate) ToPixel(zoom int) Pixel {
-	x := (c.Lon + 180) / 360.0
-	sinLat := math.Sin(c.Lat * math.Pi / 180.0)
-	y := 0.5 - math.Log((1+sinLat)/(1-sinLat))/(4*math.Pi)
-	size := float64(mapDimensions(zoom))
-	return Pixel{
-		X: int(clip(x*size+0.5, 0, size-1)),
-		Y: int(clip(y*size+0.5, 0, size-1)),
-		Z: zoom,
-	}
+	return defaultGrid().CoordinateToPixel(c, zoom)
+}
 
+// ToTile gets the tile whose Bounds contain the coord at the zoom level
+func (c Coordinate) ToTile(zoom int) Tile {
+	return defaultGrid().CoordinateToTile(c, zoom)
+}
+
+// ToTilePixel gets the TilePixel of the coord at the zoom level, which references the tile that contains it
+func (c Coordinate) ToTilePixel(zoom int) TilePixel {
+	return defaultGrid().CoordinateToTilePixel(c, zoom)
 }
 
 func (c Coordinate) String() string {
 	return fmt.Sprintf("(%v, %v)", c.Lat, c.Lon)
 }
 
+// tileXY returns the fractional tile coordinates of the coord at the zoom level.
+// Latitude is clipped to Min/MaxLat, but longitude is not so the east edge of the map is reachable.
+func (c Coordinate) tileXY(zoom int) (x, y float64) {
+	size := float64(uint64(1) << uint(zoom))
+	w := Coordinate{Lat: clip(c.Lat, MinLat, MaxLat), Lon: c.Lon}.ToWorld()
+	return w.X * size, w.Y * size
+}
+
+// containingTile returns the tile at the zoom level whose Bounds contain the coord by flooring its tile coordinates.
+// The coord is clipped to the map, so points on its east & south edges fall in the last tiles.
+func (c Coordinate) containingTile(zoom int) Tile {
+	return ClippedCoords(c.Lat, c.Lon).ToWorld().containingTile(zoom)
+}
+
+// tileCoords converts fractional tile coordinates at the zoom level to WGS84 coordinates.
+// Unlike Pixel.ToCoords, the values are not clipped so the east and south edges of the map can be reached.
+func tileCoords(x, y float64, zoom int) Coordinate {
+	size := float64(uint64(1) << uint(zoom))
+	return WorldPoint{X: x / size, Y: y / size}.ToCoords()
+}
+
+// wraps lon into [-180, 180], values already in range are unchanged
+func wrapLon(lon float64) float64 {
+	if lon >= MinLon && lon <= MaxLon {
+		return lon
+	}
+	return math.Mod(math.Mod(lon+180, 360)+360, 360) - 180
+}
+
 // ClippedCoords that have been clipped to Max/Min Lat/Lon
 // This can be used as a constructor to assert bad values will be clipped
 func ClippedCoords(lat, lon float64) Coordinate {
@@ -43,3 +73,12 @@ func ClippedCoords(lat, lon float64) Coordinate {
 		Lon: clip(lon, MinLon, MaxLon),
 	}
 }
+
+// WrappedCoords that have their longitude wrapped into [-180, 180] and latitude clipped to Max/Min Lat
+// This can be used as a constructor for unwrapped longitudes from GPS feeds or panning maps, e.g. 190 -> -170
+func WrappedCoords(lat, lon float64) Coordinate {
+	return Coordinate{
+		Lat: clip(lat, MinLat, MaxLat),
+		Lon: wrapLon(lon),
+	}
+}
@@ -19,3 +19,63 @@ func TestToPixel(t *testing.T) {
 		}
 	}
 }
+
+func TestWrappedCoords(t *testing.T) {
+	coordTests := []struct {
+		lat, lon float64
+		coords   Coordinate
+	}{
+		{40, -105, Coordinate{40, -105}},
+		{40, 180, Coordinate{40, 180}},
+		{40, -180, Coordinate{40, -180}},
+		{40, 190, Coordinate{40, -170}},
+		{40, -190, Coordinate{40, 170}},
+		{40, 540, Coordinate{40, -180}},
+		{40, 719, Coordinate{40, -1}},
+		{89, 0, Coordinate{MaxLat, 0}},
+		{-89, 0, Coordinate{MinLat, 0}},
+	}
+	errf := "WrappedCoords(%v, %v) -> %+v"
+	for _, test := range coordTests {
+		coords := WrappedCoords(test.lat, test.lon)
+		if !coords.Equals(test.coords) {
+			t.Errorf(errf, test.lat, test.lon, coords)
+		}
+	}
+}
+
+func TestCoordinateToTile(t *testing.T) {
+	if tile := WrappedCoords(40, 255).ToTile(7); tile != FromCoordinate(40, -105, 7) {
+		t.Errorf("WrappedCoords(40, 255).ToTile(7) -> %+v", tile)
+	}
+	if tile := ClippedCoords(40, 255).ToTile(7); tile != FromCoordinate(40, 180, 7) {
+		t.Errorf("ClippedCoords(40, 255).ToTile(7) -> %+v", tile)
+	}
+	// within half a pixel of the east & south edges still belongs to the tile
+	want := Tile{X: 26, Y: 48, Z: 7}
+	b := want.Bounds()
+	mercator, _ := WebMercatorQuad().FromCoordinate(Coordinate{Lat: 40, Lon: b.East - 0.0005}, 7)
+	edgeTests := []struct {
+		name string
+		tile Tile
+	}{
+		{"ToTile east", Coordinate{Lat: 40, Lon: b.East - 0.0005}.ToTile(7)},
+		{"ToTile south", Coordinate{Lat: b.South + 0.0005, Lon: b.West}.ToTile(7)},
+		{"FromCoordinate", FromCoordinate(40, b.East-0.0005, 7)},
+		{"Grid{512}.CoordinateToTile", Grid{TileSize: 512}.CoordinateToTile(Coordinate{Lat: 40, Lon: b.East - 0.0005}, 7)},
+		{"WebMercatorQuad", mercator},
+		{"Mercator.ToTile east", Coordinate{Lat: 40, Lon: b.East - 0.0005}.ToMercator().ToTile(7)},
+		{"Grid{512}.MercatorToTile south", Grid{TileSize: 512}.MercatorToTile(Coordinate{Lat: b.South + 0.0005, Lon: b.West}.ToMercator(), 7)},
+	}
+	for _, test := range edgeTests {
+		if test.tile != want {
+			t.Errorf("%s near the edge of %v -> %v", test.name, want, test.tile)
+		}
+	}
+	// the last meter of a tile's mercator extent
+	want = Tile{X: 5, Y: 5, Z: 3}
+	e := want.BoundsMercator()
+	if tile := (Mercator{X: e.MaxX - 1, Y: e.MinY + 1}).ToTile(3); tile != want {
+		t.Errorf("Mercator.ToTile near the SE corner of %v -> %v", want, tile)
+	}
+}
@@ -0,0 +1,251 @@
+package tiles
+
+import (
+	"math"
+	"sort"
+)
+
+// Geometry is a WGS84 shape that can be covered with tiles.
+// Edges are treated as straight lines in the mercator projection and are expected not to cross the antimeridian.
+type Geometry interface {
+	// cover adds the tiles at the zoom level touched by the geometry to the set.
+	// If contained is set, only tiles that are completely inside of the geometry are added.
+	cover(zoom int, contained bool, set map[Tile]struct{})
+}
+
+// LineString is a sequence of connected coordinates like a GeoJSON LineString
+type LineString []Coordinate
+
+// Polygon is a list of linear rings like a GeoJSON Polygon.
+// The first ring is the exterior and any others are holes.
+type Polygon [][]Coordinate
+
+// MultiPolygon is a list of Polygons like a GeoJSON MultiPolygon
+type MultiPolygon []Polygon
+
+// CoverOptions configures how a Geometry is covered by Cover
+type CoverOptions struct {
+	// Contained only returns tiles that are fully inside of the geometry instead of all the tiles it intersects.
+	Contained bool
+	// Compact merges any four complete siblings into their parent like Compact, which yields mixed zoom tiles.
+	Compact bool
+}
+
+// Cover returns the tiles at the zoom level that the geometry touches sorted by quadkey.
+// A LineString has no area, so it covers no tiles if opts.Contained is set.
+// Returns an error if zoom is outside of [0, ZMax]
+func Cover(g Geometry, zoom int, opts CoverOptions) ([]Tile, error) {
+	if err := ValidateZoom(zoom); err != nil {
+		return nil, err
+	}
+	set := make(map[Tile]struct{})
+	g.cover(zoom, opts.Contained, set)
+	qks := make([]Quadkey, 0, len(set))
+	for t := range set {
+		qks = append(qks, t.Quadkey())
+	}
+	if opts.Compact {
+		// the quadkeys of the covered tiles are all valid
+		qks, _ = Compact(qks)
+	}
+	sort.Sort(quadkeys(qks))
+	tiles := make([]Tile, len(qks))
+	for i, qk := range qks {
+		tiles[i] = qk.ToTile()
+	}
+	return tiles, nil
+}
+
+func (l LineString) cover(zoom int, contained bool, set map[Tile]struct{}) {
+	if contained {
+		return
+	}
+	traceLine(project([]Coordinate(l), zoom), zoom, false, set)
+}
+
+func (p Polygon) cover(zoom int, contained bool, set map[Tile]struct{}) {
+	// A tile touches the polygon if an edge crosses its interior or if its center is inside.
+	// It is contained if its center is inside and no edge crosses it.
+	crossed := make(map[Tile]struct{})
+	rings := make([][]point, len(p))
+	for i, ring := range p {
+		rings[i] = closeRing(project(ring, zoom))
+		traceLine(rings[i], zoom, true, crossed)
+	}
+	fillRings(rings, zoom, func(t Tile) {
+		if _, ok := crossed[t]; !ok {
+			set[t] = struct{}{}
+		}
+	})
+	if !contained {
+		for t := range crossed {
+			set[t] = struct{}{}
+		}
+	}
+}
+
+func (m MultiPolygon) cover(zoom int, contained bool, set map[Tile]struct{}) {
+	for _, p := range m {
+		p.cover(zoom, contained, set)
+	}
+}
+
+// point is a fractional tile coordinate
+type point struct {
+	x, y float64
+}
+
+// project converts the coordinates to fractional tile coordinates.
+// Values within float error of a tile edge are snapped to it, so shapes built from tile bounds line up.
+func project(coords []Coordinate, zoom int) []point {
+	pts := make([]point, len(coords))
+	for i, c := range coords {
+		x, y := c.tileXY(zoom)
+		pts[i] = point{x: snap(x), y: snap(y)}
+	}
+	return pts
+}
+
+func snap(v float64) float64 {
+	if r := math.Round(v); math.Abs(v-r) < 1e-8 {
+		return r
+	}
+	return v
+}
+
+// closeRing appends the first point of the ring if it isn't already closed
+func closeRing(ring []point) []point {
+	if len(ring) > 0 && ring[0] != ring[len(ring)-1] {
+		ring = append(ring, ring[0])
+	}
+	return ring
+}
+
+// traceLine adds every tile that the segments of the line pass through to the set.
+// Tiles are half open like FromCoordinate, so a segment ending on an edge touches the next tile.
+// If strict is set, only tiles whose interior is crossed by a segment are added.
+func traceLine(line []point, zoom int, strict bool, set map[Tile]struct{}) {
+	n := 1 << uint(zoom)
+	add := func(x, y int) {
+		x = int(clip(float64(x), 0, float64(n-1)))
+		y = int(clip(float64(y), 0, float64(n-1)))
+		set[Tile{X: x, Y: y, Z: zoom}] = struct{}{}
+	}
+	if len(line) == 1 && !strict {
+		add(int(math.Floor(line[0].x)), int(math.Floor(line[0].y)))
+	}
+	for i := 1; i < len(line); i++ {
+		a, b := line[i-1], line[i]
+		traceSegment(a, b, func(x, y int) {
+			if !strict || crossesCell(a, b, x, y) {
+				add(x, y)
+			}
+		})
+	}
+}
+
+// traceSegment walks the grid cells from a to b (Amanatides & Woo)
+func traceSegment(a, b point, visit func(x, y int)) {
+	x, y := int(math.Floor(a.x)), int(math.Floor(a.y))
+	ex, ey := int(math.Floor(b.x)), int(math.Floor(b.y))
+	stepX, tMaxX, tDeltaX := gridStep(a.x, b.x)
+	stepY, tMaxY, tDeltaY := gridStep(a.y, b.y)
+	steps := abs(ex-x) + abs(ey-y)
+	visit(x, y)
+	for i := 0; i < steps; i++ {
+		if tMaxX < tMaxY {
+			tMaxX += tDeltaX
+			x += stepX
+		} else {
+			tMaxY += tDeltaY
+			y += stepY
+		}
+		visit(x, y)
+	}
+}
+
+// crossesCell checks if the segment a-b passes through the open interior of the cell (Liang & Barsky)
+func crossesCell(a, b point, x, y int) bool {
+	dx, dy := b.x-a.x, b.y-a.y
+	xmin, ymin := float64(x), float64(y)
+	t0, t1 := 0.0, 1.0
+	for _, pq := range [4][2]float64{
+		{-dx, a.x - xmin},
+		{dx, xmin + 1 - a.x},
+		{-dy, a.y - ymin},
+		{dy, ymin + 1 - a.y},
+	} {
+		p, q := pq[0], pq[1]
+		if p == 0 {
+			if q <= 0 {
+				return false
+			}
+			continue
+		}
+		r := q / p
+		if p < 0 {
+			t0 = math.Max(t0, r)
+		} else {
+			t1 = math.Min(t1, r)
+		}
+	}
+	return t0 < t1
+}
+
+// gridStep returns the direction, distance to the first boundary and distance between boundaries
+// along one axis of a segment parameterized from 0 to 1.
+func gridStep(from, to float64) (step int, tMax, tDelta float64) {
+	d := to - from
+	switch {
+	case d > 0:
+		return 1, (math.Floor(from) + 1 - from) / d, 1 / d
+	case d < 0:
+		return -1, (from - math.Floor(from)) / -d, 1 / -d
+	default:
+		return 0, math.Inf(1), math.Inf(1)
+	}
+}
+
+// fillRings calls fn for every tile whose center is inside of the closed rings using the even-odd rule
+func fillRings(rings [][]point, zoom int, fn func(Tile)) {
+	n := 1 << uint(zoom)
+	ymin, ymax := math.Inf(1), math.Inf(-1)
+	for _, ring := range rings {
+		for _, p := range ring {
+			ymin = math.Min(ymin, p.y)
+			ymax = math.Max(ymax, p.y)
+		}
+	}
+	if math.IsInf(ymin, 0) {
+		return
+	}
+	rmin, rmax := edgeRange(ymin, ymax, n)
+	var xs []float64
+	for row := rmin; row <= rmax; row++ {
+		yc := float64(row) + 0.5
+		xs = xs[:0]
+		for _, ring := range rings {
+			for i := 1; i < len(ring); i++ {
+				a, b := ring[i-1], ring[i]
+				if (a.y <= yc) != (b.y <= yc) {
+					xs = append(xs, a.x+(yc-a.y)*(b.x-a.x)/(b.y-a.y))
+				}
+			}
+		}
+		sort.Float64s(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			lo := int(math.Max(math.Ceil(xs[i]-0.5), 0))
+			hi := int(math.Min(math.Floor(xs[i+1]-0.5), float64(n-1)))
+			for x := lo; x <= hi; x++ {
+				fn(Tile{X: x, Y: row, Z: zoom})
+			}
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,81 @@
+package tiles
+
+import (
+	"testing"
+)
+
+func TestCoverPolygon(t *testing.T) {
+	box := bboxPolygon(Tile{X: 2, Y: 2, Z: 3}.Bounds(), Tile{X: 3, Y: 3, Z: 3}.Bounds())
+	tri := Polygon{{{1, 1}, {1, 2}, {2, 1}}}
+	donut := Polygon{
+		bboxPolygon(Tile{X: 0, Y: 0, Z: 2}.Bounds(), Tile{X: 2, Y: 2, Z: 2}.Bounds())[0],
+		bboxPolygon(Tile{X: 1, Y: 1, Z: 2}.Bounds(), Tile{X: 1, Y: 1, Z: 2}.Bounds())[0],
+	}
+	coverTests := []struct {
+		geom  Geometry
+		zoom  int
+		opts  CoverOptions
+		tiles []Tile
+	}{
+		{box, 3, CoverOptions{}, []Tile{{2, 2, 3}, {3, 2, 3}, {2, 3, 3}, {3, 3, 3}}},
+		{box, 3, CoverOptions{Contained: true}, []Tile{{2, 2, 3}, {3, 2, 3}, {2, 3, 3}, {3, 3, 3}}},
+		{box, 3, CoverOptions{Compact: true}, []Tile{{1, 1, 2}}},
+		{box, 1, CoverOptions{}, []Tile{{0, 0, 1}}},
+		{box, 1, CoverOptions{Contained: true}, nil},
+		{tri, 5, CoverOptions{}, []Tile{FromCoordinate(1.5, 1.5, 5)}},
+		{tri, 5, CoverOptions{Contained: true}, nil},
+		{donut, 2, CoverOptions{Contained: true}, []Tile{
+			{0, 0, 2}, {1, 0, 2}, {0, 1, 2}, {2, 0, 2}, {2, 1, 2}, {0, 2, 2}, {1, 2, 2}, {2, 2, 2},
+		}},
+		{MultiPolygon{box, tri}, 3, CoverOptions{Compact: true}, []Tile{{1, 1, 2}, {4, 3, 3}}},
+	}
+	errf := "Cover(%v, %d, %+v) -> %+v"
+	for _, test := range coverTests {
+		tiles, err := Cover(test.geom, test.zoom, test.opts)
+		if err != nil || !tileSetEqual(tiles, test.tiles) {
+			t.Errorf(errf, test.geom, test.zoom, test.opts, tiles)
+		}
+	}
+}
+
+func TestCoverLineString(t *testing.T) {
+	line := LineString{{1, -179}, {1, 179}}
+	tiles, _ := Cover(line, 4, CoverOptions{})
+	if len(tiles) != 16 {
+		t.Error("LineString across the map should touch 16 tiles, got ", len(tiles))
+	}
+	if tiles, _ := Cover(line, 4, CoverOptions{Contained: true}); len(tiles) != 0 {
+		t.Error("LineString should not contain tiles, got ", tiles)
+	}
+	point := LineString{{40.7484, -73.9857}}
+	esb := FromCoordinate(40.7484, -73.9857, 18)
+	if tiles, _ := Cover(point, 18, CoverOptions{}); !tileSliceEqual(tiles, []Tile{esb}) {
+		t.Errorf("Single point LineString should cover %+v, got %+v", esb, tiles)
+	}
+	for _, zoom := range []int{-1, ZMax + 1} {
+		if _, err := Cover(line, zoom, CoverOptions{}); err == nil {
+			t.Errorf("Cover should return an error for zoom %d", zoom)
+		}
+	}
+}
+
+// bboxPolygon returns a polygon spanning from the NW corner of nw to the SE corner of se
+func bboxPolygon(nw, se BBox) Polygon {
+	return Polygon{{nw.NW(), {se.South, nw.West}, se.SE(), {nw.North, se.East}}}
+}
+
+func tileSetEqual(x, y []Tile) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	set := make(map[Tile]struct{}, len(x))
+	for _, t := range x {
+		set[t] = struct{}{}
+	}
+	for _, t := range y {
+		if _, ok := set[t]; !ok {
+			return false
+		}
+	}
+	return true
+}
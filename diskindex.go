@@ -0,0 +1,661 @@
+package tiles
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+const (
+	diskIndexMagic   = "TILEIDX"
+	diskIndexVersion = byte(3)
+	// the header is the magic, the version, the big endian uint64 offsets of the page index & the log and the CRC-32 of the offsets
+	diskIndexHeader = len(diskIndexMagic) + 1 + 8 + 8 + 4
+	// diskIndexPage is the number of segment values per entry of the page index
+	diskIndexPage = 64
+
+	// each record is framed by the big endian uint32 length & CRC-32 of its body and the CRC-32 of those 8 bytes
+	frameHeader = 12
+
+	// record ops, the body of a put is the op, the big endian uint64 Quadint & the encoded value,
+	// the body of a remove is the op & the big endian uint64 offset of the removed value
+	// and the body of the page index is the op & the big endian uint64 Quadint & offset of the first record of each page
+	opPut    = byte('p')
+	opRemove = byte('r')
+	opPages  = byte('i')
+)
+
+// errPartial is returned by readFrame for a record at the end of the log that was cut short, e.g. by a crash
+var errPartial = errors.New("partial record")
+
+// DiskIndex is a persistent TileIndex backed by a file, values are encoded with a Codec and stay on disk,
+// so the index survives restarts and can exceed RAM.
+// The file starts with a segment of the values sorted by quadkey, followed by a page index and an append-only log.
+// Only the first key of every diskIndexPage values of the segment is held in memory and range queries read just the pages
+// that can hold the tile's prefix, like KeysetIndex over boltdb.
+// Adds and removals are appended to the log, whose keys are held in memory and replayed on open until Compact
+// merges them into a new segment, so Compact periodically to bound memory and opening time.
+// Methods that can't return an error record the first one for Err. DiskIndex is thread safe
+type DiskIndex struct {
+	file  *os.File
+	path  string
+	codec Codec
+	// pages index the segment, which ends at pagesOff
+	pages    []diskPage
+	pagesOff int64
+	// logOff is the offset of the log and size is the offset that the next record is written at
+	logOff, size int64
+	// removed are the offsets of segment values that the log removed
+	removed map[int64]bool
+	// keys are the live values of the log, kept sorted so readers never have to sort under a read lock
+	keys []diskKey
+	err  error
+	sync.RWMutex
+}
+
+// diskKey is a value in the file
+type diskKey struct {
+	qk Quadint
+	// off is the offset of the encoded value, which also identifies it for tombstones
+	off int64
+	n   int
+}
+
+// diskPage is the first key of a page of the segment and the offset of its record
+type diskPage struct {
+	qk  Quadint
+	off int64
+}
+
+type byDiskKey []diskKey
+
+func (k byDiskKey) Len() int      { return len(k) }
+func (k byDiskKey) Swap(i, j int) { k[i], k[j] = k[j], k[i] }
+func (k byDiskKey) Less(i, j int) bool {
+	// values of a tile keep the order they were added in
+	return k[i].qk < k[j].qk || (k[i].qk == k[j].qk && k[i].off < k[j].off)
+}
+
+// OpenDiskIndex opens the index at the path, creating it if it doesn't exist.
+// If codec is nil, values are encoded with GobCodec.
+// A partially written record at the end of the file, e.g. from a crash, is truncated.
+// Any other invalid record is an error, so a corrupt file isn't silently cut short.
+func OpenDiskIndex(path string, codec Codec) (*DiskIndex, error) {
+	if codec == nil {
+		codec = GobCodec{}
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	idx := &DiskIndex{file: f, path: path, codec: codec, removed: make(map[int64]bool)}
+	if err := idx.load(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return idx, nil
+}
+
+// TileRange returns a channel of all tiles in the index in the zoom range
+// If zmax is greater than the deepest tile level, the deepest tile level returns
+// Acquires a readlock for duration of returned channel being open. If the segment can't be read, the error is recorded for Err.
+func (idx *DiskIndex) TileRange(zmin, zmax int) <-chan Tile {
+	tiles := make(chan Tile, 1<<10)
+	go func() {
+		defer close(tiles)
+		idx.RLock()
+		emit := func(k diskKey, next *diskKey) {
+			for z := zmin; z <= zmax && z <= k.qk.Level(); z++ {
+				q := k.qk.Ancestor(z)
+				// the next key will emit any ancestor it shares
+				if next != nil && (next.qk == q || next.qk.HasParent(q)) {
+					continue
+				}
+				tiles <- q.ToTile()
+			}
+		}
+		var prev *diskKey
+		err := idx.each(func(k diskKey) bool {
+			if prev != nil {
+				emit(*prev, &k)
+			}
+			prev = &k
+			return true
+		})
+		if prev != nil {
+			emit(*prev, nil)
+		}
+		idx.RUnlock()
+		if err != nil {
+			idx.Lock()
+			idx.setErrLocked(err)
+			idx.Unlock()
+		}
+	}()
+	return tiles
+}
+
+// Values returns a list of values aggregated under the requested tile.
+// Values that can't be read or decoded are skipped and recorded for Err.
+func (idx *DiskIndex) Values(t Tile) (vals []interface{}) {
+	idx.RLock()
+	keys, first := idx.span(t.Quadint(), true)
+	for _, k := range keys {
+		v, err := idx.read(k)
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		vals = append(vals, v)
+	}
+	idx.RUnlock()
+	if first != nil {
+		idx.Lock()
+		idx.setErrLocked(first)
+		idx.Unlock()
+	}
+	return
+}
+
+// Add encodes and appends the values to the log.
+// If any value fails to encode or the write fails, none of them are added and the error is recorded for Err.
+func (idx *DiskIndex) Add(t Tile, val ...interface{}) {
+	idx.Lock()
+	defer idx.Unlock()
+	idx.setErrLocked(idx.put(t.Quadint(), val))
+}
+
+// Remove removes the values aggregated under the tile that match and returns how many were removed
+func (idx *DiskIndex) Remove(t Tile, match func(interface{}) bool) int {
+	idx.Lock()
+	defer idx.Unlock()
+	keys, err := idx.span(t.Quadint(), true)
+	idx.setErrLocked(err)
+	var removed []diskKey
+	for _, k := range keys {
+		v, err := idx.read(k)
+		if err != nil {
+			idx.setErrLocked(err)
+			continue
+		}
+		if match(v) {
+			removed = append(removed, k)
+		}
+	}
+	if err := idx.remove(removed); err != nil {
+		idx.setErrLocked(err)
+		return 0
+	}
+	return len(removed)
+}
+
+// Delete removes all values of the tile, and of its descendants if requested, and returns how many were removed
+func (idx *DiskIndex) Delete(t Tile, descendants bool) int {
+	idx.Lock()
+	defer idx.Unlock()
+	removed, err := idx.span(t.Quadint(), descendants)
+	idx.setErrLocked(err)
+	if err := idx.remove(removed); err != nil {
+		idx.setErrLocked(err)
+		return 0
+	}
+	return len(removed)
+}
+
+// Replace sets the values of the tile, removing any it had. Replacing with no values deletes the tile.
+func (idx *DiskIndex) Replace(t Tile, val ...interface{}) {
+	idx.Lock()
+	defer idx.Unlock()
+	qk := t.Quadint()
+	removed, err := idx.span(qk, false)
+	if err == nil {
+		err = idx.remove(removed)
+	}
+	if err != nil {
+		idx.setErrLocked(err)
+		return
+	}
+	idx.setErrLocked(idx.put(qk, val))
+}
+
+// Err returns the first error that occurred in a method that couldn't return it
+func (idx *DiskIndex) Err() error {
+	idx.RLock()
+	defer idx.RUnlock()
+	return idx.err
+}
+
+// Sync commits the file to stable storage
+func (idx *DiskIndex) Sync() error {
+	idx.RLock()
+	defer idx.RUnlock()
+	return idx.file.Sync()
+}
+
+// Close syncs and closes the file, the index can't be used afterwards
+func (idx *DiskIndex) Close() error {
+	idx.Lock()
+	defer idx.Unlock()
+	if err := idx.file.Sync(); err != nil {
+		idx.file.Close()
+		return err
+	}
+	return idx.file.Close()
+}
+
+// Compact rewrites the file with the live values sorted into a new segment and an empty log, dropping removed values and tombstones.
+// The values are streamed from the old file, so compacting doesn't hold them in memory.
+// The new file is written next to the old one and renamed over it, so a failure leaves the index unchanged.
+func (idx *DiskIndex) Compact() error {
+	idx.Lock()
+	defer idx.Unlock()
+	tmp := idx.path + ".compact"
+	f, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	// the offsets in the header are written once they're known
+	w.Write(make([]byte, diskIndexHeader))
+	off := int64(diskIndexHeader)
+	var pages []diskPage
+	n := 0
+	scanErr := idx.each(func(k diskKey) bool {
+		data := make([]byte, k.n)
+		if _, err = idx.file.ReadAt(data, k.off); err != nil {
+			return false
+		}
+		if n%diskIndexPage == 0 {
+			pages = append(pages, diskPage{qk: k.qk, off: off})
+		}
+		n++
+		rec := putRecord(k.qk, data)
+		off += int64(len(rec))
+		_, err = w.Write(rec)
+		return err == nil
+	})
+	if err == nil {
+		err = scanErr
+	}
+	pagesOff := off
+	if err == nil {
+		rec := pagesRecord(pages)
+		off += int64(len(rec))
+		_, err = w.Write(rec)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		_, err = f.WriteAt(header(pagesOff, off), 0)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmp, idx.path)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	idx.file.Close()
+	idx.file, idx.pages, idx.pagesOff, idx.logOff, idx.size = f, pages, pagesOff, off, off
+	idx.removed, idx.keys = make(map[int64]bool), nil
+	return nil
+}
+
+// put appends the values of the tile to the log, assumes the write lock is held
+func (idx *DiskIndex) put(qk Quadint, vals []interface{}) error {
+	var buf bytes.Buffer
+	keys := make([]diskKey, len(vals))
+	for i, v := range vals {
+		data, err := idx.codec.Encode(v)
+		if err != nil {
+			return err
+		}
+		rec := putRecord(qk, data)
+		keys[i] = diskKey{qk: qk, off: idx.size + int64(buf.Len()+len(rec)-len(data)), n: len(data)}
+		buf.Write(rec)
+	}
+	if err := idx.write(buf.Bytes()); err != nil {
+		return err
+	}
+	// the new keys have the greatest offsets, so they go after the values the tile already has
+	i := sort.Search(len(idx.keys), func(i int) bool { return idx.keys[i].qk > qk })
+	idx.keys = append(idx.keys[:i], append(keys, idx.keys[i:]...)...)
+	return nil
+}
+
+// remove appends tombstones for the keys and drops them from the index, assumes the write lock is held
+func (idx *DiskIndex) remove(keys []diskKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	var buf []byte
+	for _, k := range keys {
+		var body [9]byte
+		body[0] = opRemove
+		binary.BigEndian.PutUint64(body[1:], uint64(k.off))
+		buf = append(buf, frame(body[:])...)
+	}
+	if err := idx.write(buf); err != nil {
+		return err
+	}
+	gone := make(map[int64]bool, len(keys))
+	for _, k := range keys {
+		if k.off < idx.pagesOff {
+			idx.removed[k.off] = true
+		} else {
+			gone[k.off] = true
+		}
+	}
+	live := idx.keys[:0]
+	for _, k := range idx.keys {
+		if !gone[k.off] {
+			live = append(live, k)
+		}
+	}
+	idx.keys = live
+	return nil
+}
+
+// write appends the records to the end of the file
+func (idx *DiskIndex) write(buf []byte) error {
+	if len(buf) == 0 {
+		return nil
+	}
+	n, err := idx.file.WriteAt(buf, idx.size)
+	if err != nil {
+		// drop whatever made it to the file so the log doesn't end with a partial record
+		idx.file.Truncate(idx.size)
+		return err
+	}
+	idx.size += int64(n)
+	return nil
+}
+
+// read decodes the value of the key from the file
+func (idx *DiskIndex) read(k diskKey) (interface{}, error) {
+	data := make([]byte, k.n)
+	if _, err := idx.file.ReadAt(data, k.off); err != nil {
+		return nil, err
+	}
+	return idx.codec.Decode(data)
+}
+
+// load checks the header, reads the page index and replays the log into the keys
+func (idx *DiskIndex) load() error {
+	info, err := idx.file.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+	if size == 0 {
+		off := int64(diskIndexHeader)
+		if _, err := idx.file.WriteAt(header(off, off), 0); err != nil {
+			return err
+		}
+		idx.pagesOff, idx.logOff, idx.size = off, off, off
+		return nil
+	}
+	h := make([]byte, diskIndexHeader)
+	if _, err := idx.file.ReadAt(h, 0); err != nil || string(h[:len(diskIndexMagic)]) != diskIndexMagic {
+		return errors.New("Invalid DiskIndex " + idx.path + ": not a tile index file")
+	}
+	if h[len(diskIndexMagic)] != diskIndexVersion {
+		return errors.New("Invalid DiskIndex " + idx.path + ": unsupported version")
+	}
+	offs := h[len(diskIndexMagic)+1:]
+	idx.pagesOff = int64(binary.BigEndian.Uint64(offs))
+	idx.logOff = int64(binary.BigEndian.Uint64(offs[8:]))
+	if crc32.ChecksumIEEE(offs[:16]) != binary.BigEndian.Uint32(offs[16:]) ||
+		idx.pagesOff < int64(diskIndexHeader) || idx.logOff < idx.pagesOff || size < idx.logOff {
+		return errors.New("Invalid DiskIndex " + idx.path + ": corrupt header")
+	}
+	if idx.logOff > idx.pagesOff {
+		if err := idx.loadPages(); err != nil {
+			return fmt.Errorf("Invalid DiskIndex %s: page index at %d: %v", idx.path, idx.pagesOff, err)
+		}
+	}
+	r := bufio.NewReader(io.NewSectionReader(idx.file, idx.logOff, size-idx.logOff))
+	off := idx.logOff
+	var keys []diskKey
+	live := make(map[int64]bool)
+	for off < size {
+		body, err := readFrame(r, off, size)
+		if err == errPartial {
+			// only the last record can be partial, it's dropped
+			break
+		}
+		if err == nil {
+			err = idx.replay(body, off, &keys, live)
+		}
+		if err != nil {
+			return fmt.Errorf("Invalid DiskIndex %s: record at %d: %v", idx.path, off, err)
+		}
+		off += int64(frameHeader + len(body))
+	}
+	if off < size {
+		if err := idx.file.Truncate(off); err != nil {
+			return err
+		}
+	}
+	idx.size = off
+	for _, k := range keys {
+		if live[k.off] {
+			idx.keys = append(idx.keys, k)
+		}
+	}
+	sort.Sort(byDiskKey(idx.keys))
+	return nil
+}
+
+// loadPages reads the page index between the segment and the log, which Compact wrote whole so it can't be partial
+func (idx *DiskIndex) loadPages() error {
+	body, err := readFrame(io.NewSectionReader(idx.file, idx.pagesOff, idx.logOff-idx.pagesOff), idx.pagesOff, idx.logOff)
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 || body[0] != opPages || (len(body)-1)%16 != 0 {
+		return errors.New("invalid page index")
+	}
+	for b := body[1:]; len(b) > 0; b = b[16:] {
+		idx.pages = append(idx.pages, diskPage{qk: Quadint(binary.BigEndian.Uint64(b)), off: int64(binary.BigEndian.Uint64(b[8:]))})
+	}
+	return nil
+}
+
+// readFrame reads the body of the record at off of a log with size bytes.
+// It returns errPartial if the log ends within the record or the last record fails its checksum
+// and an error for any other invalid record, including a corrupt length.
+func readFrame(r io.Reader, off, size int64) ([]byte, error) {
+	if size-off < frameHeader {
+		return nil, errPartial
+	}
+	var h [frameHeader]byte
+	if _, err := io.ReadFull(r, h[:]); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(h[:8]) != binary.BigEndian.Uint32(h[8:]) {
+		return nil, errors.New("corrupt record header")
+	}
+	// the length is checked, so a record that runs past the end was cut short
+	end := off + frameHeader + int64(binary.BigEndian.Uint32(h[:]))
+	if end > size {
+		return nil, errPartial
+	}
+	body := make([]byte, end-off-frameHeader)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(h[4:]) {
+		if end == size {
+			return nil, errPartial
+		}
+		return nil, errors.New("corrupt record")
+	}
+	return body, nil
+}
+
+// replay applies the body of the log record at off to the keys, the live offsets and the removed segment values
+func (idx *DiskIndex) replay(body []byte, off int64, keys *[]diskKey, live map[int64]bool) error {
+	if len(body) < 9 {
+		return errors.New("short record")
+	}
+	v := binary.BigEndian.Uint64(body[1:9])
+	switch body[0] {
+	case opPut:
+		qk := Quadint(v)
+		if qk.Level() > ZMax {
+			return errors.New("quadint level is greater than ZMax")
+		}
+		k := diskKey{qk: qk, off: off + frameHeader + 9, n: len(body) - 9}
+		*keys = append(*keys, k)
+		live[k.off] = true
+	case opRemove:
+		if int64(v) < idx.pagesOff {
+			idx.removed[int64(v)] = true
+		} else {
+			delete(live, int64(v))
+		}
+	default:
+		return errors.New("invalid record op")
+	}
+	return nil
+}
+
+// scan calls fn with the live segment values from the first key >= from in order, until fn returns false.
+// Only the pages from the one that can hold from are read and the values themselves are skipped.
+func (idx *DiskIndex) scan(from Quadint, fn func(k diskKey) bool) error {
+	start := int64(diskIndexHeader)
+	// keys equal to from can start in the page before the first one that starts at or after it
+	if i := sort.Search(len(idx.pages), func(i int) bool { return idx.pages[i].qk >= from }); i > 0 {
+		start = idx.pages[i-1].off
+	}
+	r := bufio.NewReader(io.NewSectionReader(idx.file, start, idx.pagesOff-start))
+	var h [frameHeader + 9]byte
+	for off := start; off < idx.pagesOff; {
+		if _, err := io.ReadFull(r, h[:]); err != nil {
+			return fmt.Errorf("Invalid DiskIndex %s: record at %d: %v", idx.path, off, err)
+		}
+		n := int(binary.BigEndian.Uint32(h[:])) - 9
+		if crc32.ChecksumIEEE(h[:8]) != binary.BigEndian.Uint32(h[8:]) || h[frameHeader] != opPut || n < 0 {
+			return fmt.Errorf("Invalid DiskIndex %s: record at %d: corrupt record", idx.path, off)
+		}
+		k := diskKey{qk: Quadint(binary.BigEndian.Uint64(h[frameHeader+1:])), off: off + int64(len(h)), n: n}
+		if _, err := r.Discard(n); err != nil {
+			return fmt.Errorf("Invalid DiskIndex %s: record at %d: %v", idx.path, off, err)
+		}
+		off = k.off + int64(n)
+		if k.qk < from || idx.removed[k.off] {
+			continue
+		}
+		if !fn(k) {
+			break
+		}
+	}
+	return nil
+}
+
+// each calls fn with every live value in order, merging the segment and the log, until fn returns false
+func (idx *DiskIndex) each(fn func(k diskKey) bool) error {
+	i, ok := 0, true
+	err := idx.scan(0, func(k diskKey) bool {
+		// the log values of a tile were added after its segment values
+		for ; ok && i < len(idx.keys) && idx.keys[i].qk < k.qk; i++ {
+			ok = fn(idx.keys[i])
+		}
+		ok = ok && fn(k)
+		return ok
+	})
+	for ; ok && err == nil && i < len(idx.keys); i++ {
+		ok = fn(idx.keys[i])
+	}
+	return err
+}
+
+// span returns the live values that are the tile, or the tile and its descendants, in order.
+// If the segment can't be read, the values found before the error are returned with it.
+func (idx *DiskIndex) span(qk Quadint, descendants bool) ([]diskKey, error) {
+	in := func(n Quadint) bool {
+		return n == qk || (descendants && n.HasParent(qk))
+	}
+	var keys []diskKey
+	// the tile and its descendants are contiguous in both the segment and the log
+	err := idx.scan(qk, func(k diskKey) bool {
+		if !in(k.qk) {
+			return false
+		}
+		keys = append(keys, k)
+		return true
+	})
+	lo := sort.Search(len(idx.keys), func(i int) bool { return idx.keys[i].qk >= qk })
+	hi := lo
+	for hi < len(idx.keys) && in(idx.keys[hi].qk) {
+		hi++
+	}
+	// keys is a copy, so callers can remove the values
+	keys = append(keys, idx.keys[lo:hi]...)
+	sort.Sort(byDiskKey(keys))
+	return keys, err
+}
+
+// header encodes the file header with the offsets of the page index and the log
+func header(pagesOff, logOff int64) []byte {
+	h := make([]byte, diskIndexHeader)
+	copy(h, diskIndexMagic)
+	h[len(diskIndexMagic)] = diskIndexVersion
+	offs := h[len(diskIndexMagic)+1:]
+	binary.BigEndian.PutUint64(offs, uint64(pagesOff))
+	binary.BigEndian.PutUint64(offs[8:], uint64(logOff))
+	binary.BigEndian.PutUint32(offs[16:], crc32.ChecksumIEEE(offs[:16]))
+	return h
+}
+
+// frame prefixes the body of a record with its length & checksums
+func frame(body []byte) []byte {
+	rec := make([]byte, frameHeader, frameHeader+len(body))
+	binary.BigEndian.PutUint32(rec, uint32(len(body)))
+	binary.BigEndian.PutUint32(rec[4:], crc32.ChecksumIEEE(body))
+	binary.BigEndian.PutUint32(rec[8:], crc32.ChecksumIEEE(rec[:8]))
+	return append(rec, body...)
+}
+
+// putRecord encodes a put of the data to the tile, the data is at the end of the record
+func putRecord(qk Quadint, data []byte) []byte {
+	body := make([]byte, 9, 9+len(data))
+	body[0] = opPut
+	binary.BigEndian.PutUint64(body[1:], uint64(qk))
+	return frame(append(body, data...))
+}
+
+// pagesRecord encodes the page index
+func pagesRecord(pages []diskPage) []byte {
+	body := make([]byte, 1, 1+16*len(pages))
+	body[0] = opPages
+	for _, p := range pages {
+		var b [16]byte
+		binary.BigEndian.PutUint64(b[:], uint64(p.qk))
+		binary.BigEndian.PutUint64(b[8:], uint64(p.off))
+		body = append(body, b[:]...)
+	}
+	return frame(body)
+}
+
+// setErrLocked records the first error, assumes the write lock is held
+func (idx *DiskIndex) setErrLocked(err error) {
+	if idx.err == nil {
+		idx.err = err
+	}
+}
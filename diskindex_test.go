@@ -0,0 +1,283 @@
+package tiles
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func openTestDiskIndex(t *testing.T, path string, codec Codec) *DiskIndex {
+	idx, err := OpenDiskIndex(path, codec)
+	if err != nil {
+		t.Fatal("OpenDiskIndex: ", err)
+	}
+	return idx
+}
+
+func TestDiskIndex(t *testing.T) {
+	dir := t.TempDir()
+	idx := openTestDiskIndex(t, filepath.Join(dir, "index"), nil)
+	defer idx.Close()
+	testIndex(t, idx)
+	updates := openTestDiskIndex(t, filepath.Join(dir, "updates"), nil)
+	defer updates.Close()
+	testIndexUpdates(t, updates)
+	if err := updates.Err(); err != nil {
+		t.Error("DiskIndex.Err: ", err)
+	}
+}
+
+func TestDiskIndexReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index")
+	esb := FromCoordinate(40.7484, -73.9857, 18)
+	sol := FromCoordinate(40.6892, -74.0445, 18)
+	nyc := Tile{X: 75, Y: 96, Z: 8}
+	idx := openTestDiskIndex(t, path, nil)
+	idx.Add(esb, "EmpireStateBuilding", "Bus1")
+	idx.Add(sol, "StatueOfLiberty")
+	idx.Add(esb, 42)
+	idx.Remove(nyc, func(v interface{}) bool { return v == "Bus1" })
+	if err := idx.Close(); err != nil {
+		t.Fatal("DiskIndex.Close: ", err)
+	}
+	want := []interface{}{"EmpireStateBuilding", 42, "StatueOfLiberty"}
+	idx = openTestDiskIndex(t, path, nil)
+	if vals := idx.Values(nyc); !reflect.DeepEqual(vals, want) {
+		t.Errorf("DiskIndex.Values after reopen -> %v not %v", vals, want)
+	}
+	// a crash in the middle of a write leaves a partial record
+	before := fileSize(t, path)
+	idx.Close()
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	f.Write([]byte{opPut, 0, 1, 2})
+	f.Close()
+	idx = openTestDiskIndex(t, path, nil)
+	if vals := idx.Values(nyc); !reflect.DeepEqual(vals, want) {
+		t.Errorf("DiskIndex.Values after partial write -> %v not %v", vals, want)
+	}
+	if size := fileSize(t, path); size != before {
+		t.Errorf("DiskIndex did not truncate the partial write %d -> %d", before, size)
+	}
+	idx.Replace(esb, "Ferry")
+	if err := idx.Compact(); err != nil {
+		t.Fatal("DiskIndex.Compact: ", err)
+	}
+	if size := fileSize(t, path); size >= before {
+		t.Errorf("DiskIndex.Compact did not shrink the file %d -> %d", before, size)
+	}
+	idx.Add(esb, "Bus2")
+	idx.Close()
+	want = []interface{}{"Ferry", "Bus2", "StatueOfLiberty"}
+	idx = openTestDiskIndex(t, path, nil)
+	defer idx.Close()
+	if vals := idx.Values(nyc); !reflect.DeepEqual(vals, want) {
+		t.Errorf("DiskIndex.Values after compact -> %v not %v", vals, want)
+	}
+	keyset := &KeysetIndex{}
+	keyset.Add(esb)
+	keyset.Add(sol)
+	want = nil
+	for tile := range keyset.TileRange(0, ZMax) {
+		want = append(want, tile)
+	}
+	var got []interface{}
+	for tile := range idx.TileRange(0, ZMax) {
+		got = append(got, tile)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiskIndex.TileRange -> %v not %v", got, want)
+	}
+}
+
+func TestDiskIndexConcurrent(t *testing.T) {
+	idx := openTestDiskIndex(t, filepath.Join(t.TempDir(), "index"), nil)
+	defer idx.Close()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				idx.Add(Tile{X: i, Y: g, Z: 8}, i)
+				idx.Values(Tile{})
+				for range idx.TileRange(8, 8) {
+				}
+				idx.Sync()
+				if g == 0 && i%10 == 0 {
+					idx.Compact()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if n := len(idx.Values(Tile{})); n != 200 {
+		t.Errorf("DiskIndex.Values after concurrent Adds -> %d values not 200", n)
+	}
+	var prev Quadint
+	for i, k := range idx.keys {
+		if i > 0 && k.qk < prev {
+			t.Fatalf("DiskIndex keys are not sorted at %d", i)
+		}
+		prev = k.qk
+	}
+}
+
+func TestDiskIndexPages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index")
+	idx := openTestDiskIndex(t, path, JSONCodec{})
+	keyset := &KeysetIndex{}
+	add := func(t Tile, v float64) {
+		idx.Add(t, v)
+		keyset.Add(t, v)
+	}
+	for i := 0; i < 500; i++ {
+		add(Tile{X: i % 40, Y: i / 40, Z: 10}, float64(i))
+	}
+	if err := idx.Compact(); err != nil {
+		t.Fatal("DiskIndex.Compact: ", err)
+	}
+	// after compacting only the page index is held in memory
+	if len(idx.keys) != 0 || len(idx.pages) != (500+diskIndexPage-1)/diskIndexPage {
+		t.Errorf("DiskIndex.Compact -> %d keys & %d pages in memory", len(idx.keys), len(idx.pages))
+	}
+	// values in the segment & the log are merged
+	for i := 0; i < 40; i++ {
+		add(Tile{X: i, Y: 5, Z: 10}, float64(1000+i))
+	}
+	match := func(v interface{}) bool { return int(v.(float64))%3 == 0 }
+	removeTests := []Tile{{X: 1, Y: 2, Z: 8}, {X: 0, Y: 0, Z: 5}}
+	for _, tile := range removeTests {
+		if n, want := idx.Remove(tile, match), keyset.Remove(tile, match); n != want {
+			t.Errorf("DiskIndex.Remove(%v) -> %d not %d", tile, n, want)
+		}
+	}
+	idx.Replace(Tile{X: 7, Y: 3, Z: 10}, 2000.0)
+	keyset.Replace(Tile{X: 7, Y: 3, Z: 10}, 2000.0)
+	idx.Close()
+	idx = openTestDiskIndex(t, path, JSONCodec{})
+	defer idx.Close()
+	valueTests := []Tile{{}, {X: 0, Y: 0, Z: 5}, {X: 1, Y: 2, Z: 8}, {X: 7, Y: 3, Z: 10}, {X: 39, Y: 5, Z: 10}, {X: 3, Y: 1, Z: 7}}
+	for _, tile := range valueTests {
+		if vals, want := idx.Values(tile), keyset.Values(tile); !reflect.DeepEqual(vals, want) {
+			t.Errorf("DiskIndex.Values(%v) -> %v not %v", tile, vals, want)
+		}
+	}
+	// KeysetIndex repeats a tile for each of its values, so compare the sets
+	got, want := make(map[Tile]bool), make(map[Tile]bool)
+	for tile := range idx.TileRange(0, ZMax) {
+		if got[tile] {
+			t.Errorf("DiskIndex.TileRange repeated %v", tile)
+		}
+		got[tile] = true
+	}
+	for tile := range keyset.TileRange(0, ZMax) {
+		want[tile] = true
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiskIndex.TileRange -> %d tiles not %d", len(got), len(want))
+	}
+	if err := idx.Err(); err != nil {
+		t.Error("DiskIndex.Err: ", err)
+	}
+}
+
+func TestDiskIndexErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "junk")
+	ioutil.WriteFile(path, []byte("not an index"), 0644)
+	if _, err := OpenDiskIndex(path, nil); err == nil {
+		t.Error("OpenDiskIndex should fail on a file that isn't an index")
+	}
+	// corruption in the middle of the log isn't mistaken for a partial write, but a torn last record is dropped
+	first := int64(diskIndexHeader)
+	corruptTests := []struct {
+		name string
+		off  func(size int64) int64
+		vals int
+	}{
+		{"length", func(int64) int64 { return first + 3 }, -1},
+		{"op", func(int64) int64 { return first + frameHeader }, -1},
+		{"value", func(int64) int64 { return first + frameHeader + 10 }, -1},
+		{"last value", func(size int64) int64 { return size - 1 }, 4},
+	}
+	var idx *DiskIndex
+	for _, test := range corruptTests {
+		path := filepath.Join(dir, "corrupt "+test.name)
+		idx = openTestDiskIndex(t, path, nil)
+		idx.Add(Tile{}, "a", "b", "c", "d", "e")
+		idx.Close()
+		before := fileSize(t, path)
+		f, _ := os.OpenFile(path, os.O_RDWR, 0644)
+		b := make([]byte, 1)
+		f.ReadAt(b, test.off(before))
+		f.WriteAt([]byte{^b[0]}, test.off(before))
+		f.Close()
+		reopened, err := OpenDiskIndex(path, nil)
+		if test.vals < 0 {
+			if err == nil {
+				reopened.Close()
+				t.Errorf("OpenDiskIndex should fail on a corrupt %s in the middle of the file", test.name)
+			}
+			if size := fileSize(t, path); size != before {
+				t.Errorf("OpenDiskIndex truncated a file with a corrupt %s %d -> %d", test.name, before, size)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("OpenDiskIndex with a corrupt %s -> %v", test.name, err)
+			continue
+		}
+		if vals := reopened.Values(Tile{}); len(vals) != test.vals {
+			t.Errorf("DiskIndex.Values with a corrupt %s -> %v", test.name, vals)
+		}
+		reopened.Close()
+	}
+	idx = openTestDiskIndex(t, filepath.Join(dir, "index"), JSONCodec{})
+	defer idx.Close()
+	idx.Add(Tile{}, "ok", make(chan int))
+	if idx.Err() == nil {
+		t.Error("DiskIndex.Err should record values that can't be encoded")
+	}
+	if vals := idx.Values(Tile{}); len(vals) != 0 {
+		t.Errorf("DiskIndex.Add should not add any values if one fails -> %v", vals)
+	}
+}
+
+func TestCodecs(t *testing.T) {
+	codecTests := []struct {
+		codec Codec
+		in    interface{}
+		out   interface{}
+	}{
+		{GobCodec{}, "EmpireStateBuilding", "EmpireStateBuilding"},
+		{GobCodec{}, 42, 42},
+		{GobCodec{}, 1.5, 1.5},
+		{JSONCodec{}, "EmpireStateBuilding", "EmpireStateBuilding"},
+		{JSONCodec{}, 42, 42.0},
+		{JSONCodec{}, map[string]int{"a": 1}, map[string]interface{}{"a": 1.0}},
+		{JSONCodec{}, nil, nil},
+	}
+	errf := "%T round trip %v -> %v %v"
+	for _, test := range codecTests {
+		data, err := test.codec.Encode(test.in)
+		if err != nil {
+			t.Errorf(errf, test.codec, test.in, nil, err)
+			continue
+		}
+		out, err := test.codec.Decode(data)
+		if err != nil || !reflect.DeepEqual(out, test.out) {
+			t.Errorf(errf, test.codec, test.in, out, err)
+		}
+	}
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
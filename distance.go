@@ -0,0 +1,156 @@
+package tiles
+
+import (
+	"math"
+)
+
+const (
+	toRad = math.Pi / 180
+	toDeg = 180 / math.Pi
+)
+
+// DistanceTo returns the great-circle distance in meters to that coord using the haversine formula.
+// It uses a spherical earth, so it can be off by up to ~0.5%, use VincentyDistanceTo when that matters.
+func (c Coordinate) DistanceTo(that Coordinate) float64 {
+	lat1, lat2 := c.Lat*toRad, that.Lat*toRad
+	dlat := lat2 - lat1
+	dlon := (that.Lon - c.Lon) * toRad
+	h := math.Pow(math.Sin(dlat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dlon/2), 2)
+	return 2 * EarthMeanRadiusM * math.Asin(math.Sqrt(math.Min(h, 1)))
+}
+
+// VincentyDistanceTo returns the distance in meters to that coord on the WGS84 ellipsoid using Vincenty's inverse formula.
+// It falls back to DistanceTo for nearly antipodal points where the formula doesn't converge.
+func (c Coordinate) VincentyDistanceTo(that Coordinate) float64 {
+	a := EarthRadiusM
+	f := EarthFlattening
+	b := a * (1 - f)
+	L := (that.Lon - c.Lon) * toRad
+	U1 := math.Atan((1 - f) * math.Tan(c.Lat*toRad))
+	U2 := math.Atan((1 - f) * math.Tan(that.Lat*toRad))
+	sinU1, cosU1 := math.Sincos(U1)
+	sinU2, cosU2 := math.Sincos(U2)
+	lambda := L
+	for i := 0; i < 200; i++ {
+		sinLambda, cosLambda := math.Sincos(lambda)
+		sinSigma := math.Sqrt(math.Pow(cosU2*sinLambda, 2) + math.Pow(cosU1*sinU2-sinU1*cosU2*cosLambda, 2))
+		if sinSigma == 0 {
+			return 0 // coincident points
+		}
+		cosSigma := sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma := math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha := 1 - sinAlpha*sinAlpha
+		cos2SigmaM := 0.0
+		if cos2Alpha != 0 { // equatorial line
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+		C := f / 16 * cos2Alpha * (4 + f*(4-3*cos2Alpha))
+		prev := lambda
+		lambda = L + (1-C)*f*sinAlpha*(sigma+C*sinSigma*(cos2SigmaM+C*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-prev) < 1e-12 {
+			u2 := cos2Alpha * (a*a - b*b) / (b * b)
+			A := 1 + u2/16384*(4096+u2*(-768+u2*(320-175*u2)))
+			B := u2 / 1024 * (256 + u2*(-128+u2*(74-47*u2)))
+			deltaSigma := B * sinSigma * (cos2SigmaM + B/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+				B/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+			return b * A * (sigma - deltaSigma)
+		}
+	}
+	return c.DistanceTo(that)
+}
+
+// BearingTo returns the initial great-circle bearing to that coord in degrees clockwise from north in [0, 360)
+func (c Coordinate) BearingTo(that Coordinate) float64 {
+	lat1, lat2 := c.Lat*toRad, that.Lat*toRad
+	dlon := (that.Lon - c.Lon) * toRad
+	y := math.Sin(dlon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dlon)
+	return math.Mod(math.Atan2(y, x)*toDeg+360, 360)
+}
+
+// Destination returns the coord reached by traveling meters along a great-circle from this coord at the initial bearing in degrees.
+// The longitude is wrapped into [-180, 180].
+func (c Coordinate) Destination(bearing, meters float64) Coordinate {
+	lat1, lon1 := c.Lat*toRad, c.Lon*toRad
+	brng := bearing * toRad
+	d := meters / EarthMeanRadiusM
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(brng))
+	lon2 := lon1 + math.Atan2(math.Sin(brng)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+	return Coordinate{Lat: lat2 * toDeg, Lon: wrapLon(lon2 * toDeg)}
+}
+
+// Midpoint returns the coord halfway along the great-circle between this coord and that coord
+func (c Coordinate) Midpoint(that Coordinate) Coordinate {
+	lat1, lon1 := c.Lat*toRad, c.Lon*toRad
+	lat2 := that.Lat * toRad
+	dlon := (that.Lon - c.Lon) * toRad
+	bx := math.Cos(lat2) * math.Cos(dlon)
+	by := math.Cos(lat2) * math.Sin(dlon)
+	lat := math.Atan2(math.Sin(lat1)+math.Sin(lat2), math.Sqrt(math.Pow(math.Cos(lat1)+bx, 2)+by*by))
+	lon := lon1 + math.Atan2(by, math.Cos(lat1)+bx)
+	return Coordinate{Lat: lat * toDeg, Lon: wrapLon(lon * toDeg)}
+}
+
+// TilesWithinRadius returns every tile at the zoom level whose area comes within meters of the center
+// along a great-circle, including the tile that contains it.
+// Tiles are ordered by row, then column. Radii that reach a pole include every column of the polar rows
+// and tiles across the antimeridian are included by their wrapped columns.
+// Returns an error if zoom is outside of [0, ZMax]
+func TilesWithinRadius(center Coordinate, meters float64, zoom int) (tiles []Tile, err error) {
+	if err = ValidateZoom(zoom); err != nil || meters < 0 {
+		return
+	}
+	center = Coordinate{Lat: center.Lat, Lon: wrapLon(center.Lon)}
+	for t := range TilesInBBox(radiusBBox(center, meters), zoom) {
+		if center.distanceToBBox(t.Bounds()) <= meters {
+			tiles = append(tiles, t)
+		}
+	}
+	return
+}
+
+// radiusBBox returns a box that contains the spherical cap of meters around the center
+func radiusBBox(center Coordinate, meters float64) BBox {
+	// pad the cap slightly so float error can't drop edge tiles before the exact distance check
+	d := meters/EarthMeanRadiusM + 1e-9
+	b := BBox{
+		North: math.Min(center.Lat+d*toDeg, 90),
+		South: math.Max(center.Lat-d*toDeg, -90),
+		West:  MinLon,
+		East:  MaxLon,
+	}
+	if b.North == 90 || b.South == -90 {
+		return b
+	}
+	dlon := math.Asin(math.Min(math.Sin(d)/math.Cos(center.Lat*toRad), 1)) * toDeg
+	if dlon >= 90 {
+		return b
+	}
+	b.West = wrapLon(center.Lon - dlon)
+	b.East = wrapLon(center.Lon + dlon)
+	return b
+}
+
+// distanceToBBox returns the great-circle distance in meters from the coord to the nearest point of the box
+func (c Coordinate) distanceToBBox(b BBox) float64 {
+	if b.containsLon(c.Lon) {
+		// the meridian through the coord is the shortest path to the box
+		return c.DistanceTo(Coordinate{Lat: clip(c.Lat, b.South, b.North), Lon: c.Lon})
+	}
+	// otherwise the nearest point is on one of the meridian edges
+	return math.Min(c.distanceToMeridian(b.West, b.South, b.North), c.distanceToMeridian(b.East, b.South, b.North))
+}
+
+// distanceToMeridian returns the great-circle distance in meters from the coord to the meridian at lon between the lats
+func (c Coordinate) distanceToMeridian(lon, south, north float64) float64 {
+	d := math.Min(c.DistanceTo(Coordinate{Lat: south, Lon: lon}), c.DistanceTo(Coordinate{Lat: north, Lon: lon}))
+	lat, dlon := c.Lat*toRad, (lon-c.Lon)*toRad
+	if cos := math.Cos(dlon); cos > 0 {
+		// latitude of the point on the meridian nearest to the coord
+		if nearest := math.Atan2(math.Sin(lat), math.Cos(lat)*cos) * toDeg; nearest > south && nearest < north {
+			d = math.Min(d, c.DistanceTo(Coordinate{Lat: nearest, Lon: lon}))
+		}
+	}
+	return d
+}
@@ -0,0 +1,187 @@
+package tiles
+
+import (
+	"math"
+	"testing"
+)
+
+var (
+	esb = Coordinate{40.7484, -73.9857}
+	bbn = Coordinate{51.5007, -0.1246}
+)
+
+func TestDistanceTo(t *testing.T) {
+	distTests := []struct {
+		a, b     Coordinate
+		haver    float64
+		vincenty float64
+	}{
+		{esb, esb, 0, 0},
+		{Coordinate{0, 0}, Coordinate{0, 1}, 111195.08, 111319.49},
+		{Coordinate{0, 179.5}, Coordinate{0, -179.5}, 111195.08, 111319.49},
+		{esb, bbn, 5566862.15, 5581867.96},
+	}
+	errf := "Coordinate%v.%s(%v) -> %v"
+	for _, test := range distTests {
+		if d := test.a.DistanceTo(test.b); math.Abs(d-test.haver) > 1 {
+			t.Errorf(errf, test.a, "DistanceTo", test.b, d)
+		}
+		if d := test.a.VincentyDistanceTo(test.b); math.Abs(d-test.vincenty) > 1 {
+			t.Errorf(errf, test.a, "VincentyDistanceTo", test.b, d)
+		}
+	}
+}
+
+func TestBearingTo(t *testing.T) {
+	bearingTests := []struct {
+		a, b    Coordinate
+		bearing float64
+	}{
+		{Coordinate{0, 0}, Coordinate{1, 0}, 0},
+		{Coordinate{0, 0}, Coordinate{0, 1}, 90},
+		{Coordinate{0, 0}, Coordinate{-1, 0}, 180},
+		{Coordinate{0, 0}, Coordinate{0, -1}, 270},
+		{Coordinate{0, 179.5}, Coordinate{0, -179.5}, 90},
+	}
+	errf := "Coordinate%v.BearingTo(%v) -> %v"
+	for _, test := range bearingTests {
+		if b := test.a.BearingTo(test.b); math.Abs(b-test.bearing) > 1e-9 {
+			t.Errorf(errf, test.a, test.b, b)
+		}
+	}
+}
+
+func TestDestination(t *testing.T) {
+	destTests := []struct {
+		c       Coordinate
+		bearing float64
+		meters  float64
+		dest    Coordinate
+	}{
+		{Coordinate{0, 0}, 90, 0, Coordinate{0, 0}},
+		{Coordinate{0, 179.5}, 90, 111195.08, Coordinate{0, -179.5}},
+		{Coordinate{0, 0}, 0, math.Pi / 4 * EarthMeanRadiusM, Coordinate{45, 0}},
+	}
+	errf := "Coordinate%v.Destination(%v, %v) -> %v"
+	for _, test := range destTests {
+		d := test.c.Destination(test.bearing, test.meters)
+		if math.Abs(d.Lat-test.dest.Lat) > 1e-6 || math.Abs(d.Lon-test.dest.Lon) > 1e-6 {
+			t.Errorf(errf, test.c, test.bearing, test.meters, d)
+		}
+	}
+	// round trip
+	d := esb.Destination(esb.BearingTo(bbn), esb.DistanceTo(bbn))
+	if d.DistanceTo(bbn) > 1e-3 {
+		t.Errorf("Destination round trip -> %v not %v", d, bbn)
+	}
+}
+
+func TestMidpoint(t *testing.T) {
+	mid := esb.Midpoint(bbn)
+	if a, b := esb.DistanceTo(mid), mid.DistanceTo(bbn); math.Abs(a-b) > 1e-3 {
+		t.Errorf("Coordinate%v.Midpoint(%v) -> %v is not halfway %v %v", esb, bbn, mid, a, b)
+	}
+	if mid := (Coordinate{0, 179}).Midpoint(Coordinate{0, -179}); !mid.Equals(Coordinate{0, 180}) && !mid.Equals(Coordinate{0, -180}) {
+		t.Errorf("Midpoint across the antimeridian -> %v", mid)
+	}
+}
+
+func TestTilesWithinRadius(t *testing.T) {
+	radiusTests := []struct {
+		center Coordinate
+		meters float64
+		zoom   int
+	}{
+		{esb, 0, 7},
+		{esb, 20000, 7},
+		{esb, 500000, 6},
+		{Coordinate{0, 0}, 100000, 7},
+		{Coordinate{10, 179.9}, 200000, 7},
+		{Coordinate{-10, -179.9}, 200000, 7},
+		{Coordinate{84, 30}, 300000, 5},
+		{Coordinate{-89, -120}, 700000, 5},
+		{Coordinate{60, 0}, 5000000, 3},
+		{Coordinate{0, 0}, 30000000, 2},
+	}
+	errf := "TilesWithinRadius(%v, %v, %d) %s %v at %v"
+	for _, test := range radiusTests {
+		got := make(map[Tile]bool)
+		tiles, err := TilesWithinRadius(test.center, test.meters, test.zoom)
+		if err != nil {
+			t.Errorf(errf, test.center, test.meters, test.zoom, "error", err, nil)
+		}
+		for _, tile := range tiles {
+			got[tile] = true
+		}
+		if tile := test.center.ToTile(test.zoom); !got[tile] {
+			t.Errorf(errf, test.center, test.meters, test.zoom, "missing center", tile, 0)
+		}
+		n := 1 << uint(test.zoom)
+		slack := 2 * math.Pi * EarthMeanRadiusM / float64(n*sampleSteps)
+		// the oracle samples the cap independently of radiusBBox
+		checked := capTiles(test.center, test.meters, test.zoom)
+		for tile := range checked {
+			// sampling the edges overestimates the nearest distance by up to half a step
+			d := sampledDistance(test.center, tile.Bounds())
+			switch {
+			case d <= test.meters && !got[tile]:
+				t.Errorf(errf, test.center, test.meters, test.zoom, "missing", tile, d)
+			case d > test.meters+slack && got[tile]:
+				t.Errorf(errf, test.center, test.meters, test.zoom, "includes", tile, d)
+			}
+		}
+	}
+	if tiles, _ := TilesWithinRadius(esb, -1, 10); len(tiles) != 0 {
+		t.Errorf("TilesWithinRadius with a negative radius -> %v", tiles)
+	}
+	for _, zoom := range []int{-1, ZMax + 1, 63} {
+		if tiles, err := TilesWithinRadius(esb, 1000, zoom); err == nil {
+			t.Errorf("TilesWithinRadius with zoom %d -> %v", zoom, tiles)
+		}
+	}
+}
+
+const sampleSteps = 40
+
+// capTiles returns the tiles that contain points sampled across the cap of meters around the center and their neighbors.
+// The samples are closer together than the smallest tile on the map, so every tile that the cap touches is included.
+func capTiles(center Coordinate, meters float64, zoom int) map[Tile]bool {
+	n := 1 << uint(zoom)
+	step := 2 * math.Pi * EarthMeanRadiusM * math.Cos(MaxLat*toRad) / float64(n) / 2
+	tiles := make(map[Tile]bool)
+	for r := 0.0; ; r += step {
+		r = math.Min(r, meters)
+		circumference := 2 * math.Pi * EarthMeanRadiusM * math.Abs(math.Sin(r/EarthMeanRadiusM))
+		bearings := int(math.Ceil(circumference/step)) + 1
+		for i := 0; i < bearings; i++ {
+			c := center.Destination(360*float64(i)/float64(bearings), r).ToTile(zoom)
+			for dx := -1; dx <= 1; dx++ {
+				for dy := -1; dy <= 1; dy++ {
+					if y := c.Y + dy; y >= 0 && y < n {
+						tiles[Tile{X: (c.X + dx + n) % n, Y: y, Z: zoom}] = true
+					}
+				}
+			}
+		}
+		if r == meters {
+			return tiles
+		}
+	}
+}
+
+// sampledDistance is the smallest distance from c to points along the edges of b or 0 if b contains c
+func sampledDistance(c Coordinate, b BBox) float64 {
+	if b.Contains(c) {
+		return 0
+	}
+	d := math.Inf(1)
+	for i := 0; i <= sampleSteps; i++ {
+		f := float64(i) / sampleSteps
+		lat := b.South + f*(b.North-b.South)
+		lon := b.West + f*(b.East-b.West)
+		for _, p := range []Coordinate{{lat, b.West}, {lat, b.East}, {b.North, lon}, {b.South, lon}} {
+			d = math.Min(d, c.DistanceTo(p))
+		}
+	}
+	return d
+}
@@ -0,0 +1,204 @@
+package tiles
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseTile parses a tile from the forms "z/x/y", "z-x-y", "x,y,z" or a quadkey.
+// Returns an error if the string doesn't match any of them or if the tile is invalid.
+func ParseTile(s string) (Tile, error) {
+	s = strings.TrimSpace(s)
+	var sep string
+	switch {
+	case s == "":
+		return Tile{}, errors.New("Invalid Tile: empty string")
+	case strings.Contains(s, "/"):
+		sep = "/"
+	case strings.Contains(s, ","):
+		sep = ","
+	case strings.Contains(s, "-"):
+		sep = "-"
+	default:
+		return Quadkey(s).ToTileChecked()
+	}
+	vals, err := parseInts(s, sep, 3)
+	if err != nil {
+		return Tile{}, fmt.Errorf("Invalid Tile %q: %v", s, err)
+	}
+	if sep == "," {
+		return NewTile(vals[0], vals[1], vals[2])
+	}
+	return NewTile(vals[1], vals[2], vals[0])
+}
+
+// ParseCoordinate parses a coordinate from the forms "lat,lon" or "(lat, lon)" as written by Coordinate.String
+func ParseCoordinate(s string) (Coordinate, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		s = s[1 : len(s)-1]
+	}
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return Coordinate{}, fmt.Errorf("Invalid Coordinate %q: expected lat,lon", s)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return Coordinate{}, fmt.Errorf("Invalid Coordinate %q: %v", s, err)
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return Coordinate{}, fmt.Errorf("Invalid Coordinate %q: %v", s, err)
+	}
+	return Coordinate{Lat: lat, Lon: lon}, nil
+}
+
+// parseInts splits s by sep and parses exactly n ints
+func parseInts(s, sep string, n int) ([]int, error) {
+	parts := strings.Split(s, sep)
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d values separated by %q", n, sep)
+	}
+	vals := make([]int, n)
+	for i, p := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+// String returns the tile in the "z/x/y" form
+func (t Tile) String() string {
+	return fmt.Sprintf("%d/%d/%d", t.Z, t.X, t.Y)
+}
+
+// MarshalText implements encoding.TextMarshaler with the "z/x/y" form
+func (t Tile) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler with any of the forms accepted by ParseTile
+func (t *Tile) UnmarshalText(text []byte) (err error) {
+	*t, err = ParseTile(string(text))
+	return
+}
+
+// MarshalJSON implements json.Marshaler as a "z/x/y" string
+func (t Tile) MarshalJSON() ([]byte, error) {
+	return marshalJSONText(t)
+}
+
+// UnmarshalJSON implements json.Unmarshaler from a string accepted by ParseTile or an {"X", "Y", "Z"} object
+func (t *Tile) UnmarshalJSON(data []byte) error {
+	type tile Tile
+	return unmarshalJSONText(data, t, (*tile)(t))
+}
+
+// MarshalText implements encoding.TextMarshaler and returns an error if the quadkey is invalid
+func (q Quadkey) MarshalText() ([]byte, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	return []byte(q), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler and returns an error if the quadkey is invalid
+func (q *Quadkey) UnmarshalText(text []byte) error {
+	qk := Quadkey(text)
+	if err := qk.Validate(); err != nil {
+		return err
+	}
+	*q = qk
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler as a string and returns an error if the quadkey is invalid
+func (q Quadkey) MarshalJSON() ([]byte, error) {
+	return marshalJSONText(q)
+}
+
+// UnmarshalJSON implements json.Unmarshaler from a string and returns an error if the quadkey is invalid
+func (q *Quadkey) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONText(data, q, nil)
+}
+
+// MarshalText implements encoding.TextMarshaler with the "lat,lon" form
+func (c Coordinate) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatFloat(c.Lat, 'f', -1, 64) + "," + strconv.FormatFloat(c.Lon, 'f', -1, 64)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler with any of the forms accepted by ParseCoordinate
+func (c *Coordinate) UnmarshalText(text []byte) (err error) {
+	*c, err = ParseCoordinate(string(text))
+	return
+}
+
+// MarshalJSON implements json.Marshaler as a "lat,lon" string
+func (c Coordinate) MarshalJSON() ([]byte, error) {
+	return marshalJSONText(c)
+}
+
+// UnmarshalJSON implements json.Unmarshaler from a string accepted by ParseCoordinate or a {"Lat", "Lon"} object
+func (c *Coordinate) UnmarshalJSON(data []byte) error {
+	type coordinate Coordinate
+	return unmarshalJSONText(data, c, (*coordinate)(c))
+}
+
+// MarshalText implements encoding.TextMarshaler with the "z/x/y" form
+func (p Pixel) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d/%d/%d", p.Z, p.X, p.Y)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler from the "z/x/y" form
+// Returns an error if the zoom is outside of [0, ZMax]
+func (p *Pixel) UnmarshalText(text []byte) error {
+	vals, err := parseInts(strings.TrimSpace(string(text)), "/", 3)
+	if err != nil {
+		return fmt.Errorf("Invalid Pixel %q: %v", text, err)
+	}
+	if err := ValidateZoom(vals[0]); err != nil {
+		return err
+	}
+	*p = Pixel{X: vals[1], Y: vals[2], Z: vals[0]}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler as a "z/x/y" string
+func (p Pixel) MarshalJSON() ([]byte, error) {
+	return marshalJSONText(p)
+}
+
+// UnmarshalJSON implements json.Unmarshaler from a "z/x/y" string or an {"X", "Y", "Z"} object
+func (p *Pixel) UnmarshalJSON(data []byte) error {
+	type pixel Pixel
+	return unmarshalJSONText(data, p, (*pixel)(p))
+}
+
+// marshalJSONText encodes the text form of v as a JSON string
+func marshalJSONText(v encoding.TextMarshaler) ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// unmarshalJSONText decodes a JSON string with v.UnmarshalText.
+// If obj is not nil, a JSON object is decoded into it, which should not have any of the JSON methods.
+func unmarshalJSONText(data []byte, v encoding.TextUnmarshaler, obj interface{}) error {
+	if obj != nil && len(data) > 0 && data[0] == '{' {
+		return json.Unmarshal(data, obj)
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(s))
+}
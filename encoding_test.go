@@ -0,0 +1,116 @@
+package tiles
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseTile(t *testing.T) {
+	tileTests := []struct {
+		s    string
+		tile Tile
+		err  bool
+	}{
+		{"7/26/48", Tile{26, 48, 7}, false},
+		{" 7/26/48 ", Tile{26, 48, 7}, false},
+		{"7-26-48", Tile{26, 48, 7}, false},
+		{"26,48,7", Tile{26, 48, 7}, false},
+		{"26, 48, 7", Tile{26, 48, 7}, false},
+		{"0231010", Tile{26, 48, 7}, false},
+		{"0", Tile{0, 0, 1}, false},
+		{"", Tile{}, true},
+		{"7/26", Tile{}, true},
+		{"7/26/48/1", Tile{}, true},
+		{"7/a/48", Tile{}, true},
+		{"7/128/48", Tile{}, true},
+		{"0241010", Tile{}, true},
+	}
+	errf := "ParseTile(%q) -> %+v, %v"
+	for _, test := range tileTests {
+		tile, err := ParseTile(test.s)
+		if (err != nil) != test.err || tile != test.tile {
+			t.Errorf(errf, test.s, tile, err)
+		}
+	}
+}
+
+func TestParseCoordinate(t *testing.T) {
+	c := Coordinate{40.7484, -73.9857}
+	coordTests := []struct {
+		s   string
+		err bool
+	}{
+		{"40.7484,-73.9857", false},
+		{" 40.7484, -73.9857 ", false},
+		{c.String(), false},
+		{"40.7484", true},
+		{"40.7484,x", true},
+	}
+	errf := "ParseCoordinate(%q) -> %+v, %v"
+	for _, test := range coordTests {
+		coords, err := ParseCoordinate(test.s)
+		if (err != nil) != test.err || (!test.err && coords != c) {
+			t.Errorf(errf, test.s, coords, err)
+		}
+	}
+}
+
+func TestTileString(t *testing.T) {
+	tile := Tile{26, 48, 7}
+	if s := tile.String(); s != "7/26/48" {
+		t.Errorf("Tile%+v.String() -> %q", tile, s)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	type doc struct {
+		Tile    Tile
+		Quadkey Quadkey
+		Coords  Coordinate
+		Pixel   Pixel
+		Tiles   map[Tile]int
+	}
+	in := doc{
+		Tile:    Tile{26, 48, 7},
+		Quadkey: "0231010",
+		Coords:  Coordinate{40.7484, -73.9857},
+		Pixel:   Pixel{6827, 12405, 7},
+		Tiles:   map[Tile]int{{1, 1, 1}: 2},
+	}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"Tile":"7/26/48","Quadkey":"0231010","Coords":"40.7484,-73.9857","Pixel":"7/6827/12405","Tiles":{"1/1/1":2}}`
+	if string(b) != want {
+		t.Errorf("json.Marshal() -> %s", b)
+	}
+	var out doc
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Tile != in.Tile || out.Quadkey != in.Quadkey || out.Coords != in.Coords || out.Pixel != in.Pixel || out.Tiles[Tile{1, 1, 1}] != 2 {
+		t.Errorf("json.Unmarshal(%s) -> %+v", b, out)
+	}
+}
+
+func TestJSONUnmarshal(t *testing.T) {
+	var tile Tile
+	if err := json.Unmarshal([]byte(`{"X": 26, "Y": 48, "Z": 7}`), &tile); err != nil || tile != (Tile{26, 48, 7}) {
+		t.Errorf("json.Unmarshal() object -> %+v, %v", tile, err)
+	}
+	if err := json.Unmarshal([]byte(`"7/128/48"`), &tile); err == nil {
+		t.Errorf("json.Unmarshal() invalid tile -> %+v", tile)
+	}
+	var c Coordinate
+	if err := json.Unmarshal([]byte(`{"Lat": 1.5, "Lon": 2}`), &c); err != nil || c != (Coordinate{1.5, 2}) {
+		t.Errorf("json.Unmarshal() object -> %+v, %v", c, err)
+	}
+	var q Quadkey
+	if err := json.Unmarshal([]byte(`"0124"`), &q); err == nil {
+		t.Errorf("json.Unmarshal() invalid quadkey -> %q", q)
+	}
+	if _, err := json.Marshal(Quadkey("0124")); err == nil {
+		t.Error("json.Marshal() invalid quadkey should be an error")
+	}
+}
@@ -0,0 +1,179 @@
+package tiles
+
+import (
+	"math"
+)
+
+// Grid is a web mercator tiling with its own tile size in pixels.
+// The package level conversions use a Grid with the package TileSize.
+// Different Grids are safe to use concurrently, e.g. one for 256 and one for 512 (retina) tiles.
+// TileSize must be positive.
+type Grid struct {
+	TileSize int
+}
+
+// defaultGrid returns the grid used by the package level conversions
+func defaultGrid() Grid {
+	return Grid{TileSize: TileSize}
+}
+
+// MapDimensions gets the size of the x, y dimensions in pixels at the given zoom level
+// x == y since the map is a square
+// The zoom isn't validated, use ValidateZoom for untrusted zooms
+func (g Grid) MapDimensions(zoom int) int {
+	return g.TileSize << uint(zoom)
+}
+
+// Gets the size of the x, y dimensions in pixels at a fractional zoom level
+func (g Grid) floatMapDimensions(zoom float64) float64 {
+	return float64(g.TileSize) * math.Exp2(zoom)
+}
+
+// CoordinateToPixel gets the Pixel of the coord at the zoom level
+func (g Grid) CoordinateToPixel(c Coordinate, zoom int) Pixel {
+	return g.WorldToPixel(c.ToWorld(), zoom)
+}
+
+// CoordinateToTilePixel gets the TilePixel of the coord at the zoom level, which references the tile that contains it.
+// The pixel is floored instead of rounded like CoordinateToPixel, so its Tile is the same as CoordinateToTile.
+func (g Grid) CoordinateToTilePixel(c Coordinate, zoom int) TilePixel {
+	size := float64(g.MapDimensions(zoom))
+	w := ClippedCoords(c.Lat, c.Lon).ToWorld()
+	_, tp := g.PixelToTile(Pixel{
+		X: int(clip(math.Floor(w.X*size), 0, size-1)),
+		Y: int(clip(math.Floor(w.Y*size), 0, size-1)),
+		Z: zoom,
+	})
+	return tp
+}
+
+// CoordinateToTile gets the tile that contains the coord at the zoom level.
+// Unlike CoordinateToPixel it doesn't round, so the tile's Bounds always contain the coord. The tile is the same for any TileSize.
+func (g Grid) CoordinateToTile(c Coordinate, zoom int) Tile {
+	return c.containingTile(zoom)
+}
+
+// FromCoordinate take float lat/lons and a zoom and return a tile
+// Clips the coordinates if they are outside of Min/MaxLat/Lon
+func (g Grid) FromCoordinate(lat, lon float64, zoom int) Tile {
+	return g.CoordinateToTile(ClippedCoords(lat, lon), zoom)
+}
+
+// WorldToPixel gets the Pixel nearest to the point at the zoom level, clipped to the map
+func (g Grid) WorldToPixel(w WorldPoint, zoom int) Pixel {
+	size := float64(g.MapDimensions(zoom))
+	return Pixel{
+		X: int(clip(w.X*size+0.5, 0, size-1)),
+		Y: int(clip(w.Y*size+0.5, 0, size-1)),
+		Z: zoom,
+	}
+}
+
+// WorldToFloatPixel scales the point to a sub-pixel position at the zoom level, which may be fractional
+func (g Grid) WorldToFloatPixel(w WorldPoint, zoom float64) FloatPixel {
+	size := g.floatMapDimensions(zoom)
+	return FloatPixel{X: w.X * size, Y: w.Y * size, Z: zoom}
+}
+
+// FloatPixelToWorld normalizes the pixel to a WorldPoint
+func (g Grid) FloatPixelToWorld(p FloatPixel) WorldPoint {
+	size := g.floatMapDimensions(p.Z)
+	return WorldPoint{X: p.X / size, Y: p.Y / size}
+}
+
+// PixelToCoords converts the pixel to WGS84 coordinates, clipped to the map
+func (g Grid) PixelToCoords(p Pixel) Coordinate {
+	size := float64(g.MapDimensions(p.Z))
+	clipped := Pixel{
+		X: int(clip(p.floatX(), 0, size-1)),
+		Y: int(clip(p.floatY(), 0, size-1)),
+		Z: p.Z,
+	}
+	c := g.FloatPixelToWorld(clipped.ToFloat()).ToCoords()
+	return ClippedCoords(c.Lat, c.Lon)
+}
+
+// PixelToTile gets the tile that contains the pixel as well as the offset pixel within that tile.
+func (g Grid) PixelToTile(p Pixel) (tile Tile, offset TilePixel) {
+	tile = Tile{
+		X: p.X / g.TileSize,
+		Y: p.Y / g.TileSize,
+		Z: p.Z,
+	}
+	offset = TilePixel{
+		X:    p.X % g.TileSize,
+		Y:    p.Y % g.TileSize,
+		Tile: &tile,
+	}
+	return
+}
+
+// PixelToMercator converts the NW corner of the pixel to Web Mercator meters
+func (g Grid) PixelToMercator(p Pixel) Mercator {
+	return g.FloatPixelToWorld(p.ToFloat()).ToMercator()
+}
+
+// MercatorToPixel gets the Pixel of the point at the zoom level
+func (g Grid) MercatorToPixel(m Mercator, zoom int) Pixel {
+	return g.WorldToPixel(m.ToWorld(), zoom)
+}
+
+// MercatorToTile gets the tile that contains the point at the zoom level.
+// Like CoordinateToTile it doesn't round, so the tile's BoundsMercator contain the point.
+func (g Grid) MercatorToTile(m Mercator, zoom int) Tile {
+	return m.ToWorld().containingTile(zoom)
+}
+
+// TileToPixel return the NW pixel of the tile
+func (g Grid) TileToPixel(t Tile) Pixel {
+	return Pixel{
+		X: t.X * g.TileSize,
+		Y: t.Y * g.TileSize,
+		Z: t.Z,
+	}
+}
+
+// TilePixelToPixel converts the TilePixel to a Pixel in the whole map at the zoom of its tile
+// Panics if the Tile field is nil
+func (g Grid) TilePixelToPixel(p TilePixel) Pixel {
+	pixel := g.TileToPixel(*p.Tile)
+	pixel.X += p.X
+	pixel.Y += p.Y
+	return pixel
+}
+
+// TilePixelToCoords converts the TilePixel to WGS84 coordinates
+// Panics if the Tile field is nil
+func (g Grid) TilePixelToCoords(p TilePixel) Coordinate {
+	return g.PixelToCoords(g.TilePixelToPixel(p))
+}
+
+// GroundResolution gets the ground resolution (meters/pixel) of the map at the lat and zoom
+func (g Grid) GroundResolution(lat float64, zoom int) float64 {
+	lat = clip(lat, MinLat, MaxLat)
+	dim := float64(g.MapDimensions(zoom))
+	return math.Cos(lat*math.Pi/180) * 2 * math.Pi * EarthRadiusM / dim
+}
+
+// MapScale gets the map scale at the lat, zoom & screen DPI expressed as the denominator N of the ratio 1 : N.
+func (g Grid) MapScale(lat float64, zoom, dpi int) float64 {
+	d := float64(dpi)
+	return g.GroundResolution(lat, zoom) * d / 0.0254
+}
+
+// ZoomForResolution gets the lowest zoom whose ground resolution at the lat is at least as fine as metersPerPixel.
+// The zoom is clipped to [0, ZMax]
+func (g Grid) ZoomForResolution(lat, metersPerPixel float64) int {
+	z := 0
+	// tolerance so resolutions taken from GroundResolution map back to their zoom
+	for z < ZMax && g.GroundResolution(lat, z) > metersPerPixel*(1+1e-9) {
+		z++
+	}
+	return z
+}
+
+// ZoomForScale gets the lowest zoom whose map scale at the lat & screen DPI is at least as detailed as 1 : scale.
+// The zoom is clipped to [0, ZMax]
+func (g Grid) ZoomForScale(lat, scale float64, dpi int) int {
+	return g.ZoomForResolution(lat, scale*0.0254/float64(dpi))
+}
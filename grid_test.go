@@ -0,0 +1,56 @@
+package tiles
+
+import (
+	"testing"
+)
+
+func TestGridDefault(t *testing.T) {
+	g := Grid{TileSize: 256}
+	c := Coordinate{40.0, -105.0}
+	if p := g.CoordinateToPixel(c, 7); p != c.ToPixel(7) {
+		t.Errorf("Grid%+v.CoordinateToPixel(%v, 7) -> %+v", g, c, p)
+	}
+	if tile := g.FromCoordinate(c.Lat, c.Lon, 7); tile != FromCoordinate(c.Lat, c.Lon, 7) {
+		t.Errorf("Grid%+v.FromCoordinate(%v, 7) -> %+v", g, c, tile)
+	}
+}
+
+func TestGridRetina(t *testing.T) {
+	g := Grid{TileSize: 512}
+	gridTests := []struct {
+		coords Coordinate
+		zoom   int
+		pixel  Pixel
+		tile   Tile
+	}{
+		{Coordinate{40.0, -105.0}, 7, Pixel{13653, 24811, 7}, Tile{26, 48, 7}},
+		{Coordinate{40.7484, -73.9857}, 18, Pixel{39524996, 50445773, 18}, FromCoordinate(40.7484, -73.9857, 18)},
+	}
+	errf := "Grid%+v %s(%+v, %d) -> %+v"
+	for _, test := range gridTests {
+		p := g.CoordinateToPixel(test.coords, test.zoom)
+		if p != test.pixel {
+			t.Errorf(errf, g, "CoordinateToPixel", test.coords, test.zoom, p)
+		}
+		if tile, _ := g.PixelToTile(p); tile != test.tile {
+			t.Errorf(errf, g, "PixelToTile", p, test.zoom, tile)
+		}
+		if tile := g.FromCoordinate(test.coords.Lat, test.coords.Lon, test.zoom); tile != test.tile {
+			t.Errorf(errf, g, "FromCoordinate", test.coords, test.zoom, tile)
+		}
+		if tp := g.TileToPixel(test.tile); tp.X != test.tile.X*512 || tp.Y != test.tile.Y*512 {
+			t.Errorf(errf, g, "TileToPixel", test.tile, test.zoom, tp)
+		}
+	}
+	lat := 40.0
+	if res, half := g.GroundResolution(lat, 7), GroundResolution(lat, 7)/2; !floatEquals(res, half) {
+		t.Errorf("Grid%+v.GroundResolution(%v, 7) -> %v not %v", g, lat, res, half)
+	}
+	tp := g.CoordinateToTilePixel(Coordinate{40.0, -105.0}, 7)
+	if tp.X != 341 || tp.Y != 234 {
+		t.Errorf("Grid%+v.CoordinateToTilePixel() -> %+v", g, tp)
+	}
+	if p := g.TilePixelToPixel(tp); p != (Pixel{13653, 24810, 7}) {
+		t.Errorf("Grid%+v.TilePixelToPixel(%+v) -> %+v", g, tp, p)
+	}
+}
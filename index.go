@@ -13,10 +13,17 @@ const (
 
 // TileIndex stores indexes values by tile.
 // If a deep level of tile is added and a shallower one is requested, the values are aggregated up.
+// Remove matches values aggregated under the tile like Values, Delete & Replace only touch the tile itself unless descendants are requested.
 type TileIndex interface {
 	TileRange(zmin, zmax int) <-chan Tile
 	Values(t Tile) (vals []interface{})
 	Add(t Tile, val ...interface{})
+	// Remove removes the values under the tile that match and returns how many were removed
+	Remove(t Tile, match func(interface{}) bool) int
+	// Delete removes all values of the tile, and of its descendants if requested, and returns how many were removed
+	Delete(t Tile, descendants bool) int
+	// Replace sets the values of the tile, removing any it had. Replacing with no values deletes the tile.
+	Replace(t Tile, val ...interface{})
 }
 
 // NewTileIndex returns the default TileIndex
@@ -25,14 +32,15 @@ func NewTileIndex() TileIndex {
 }
 
 // KeysetIndex is a TileIndex implementation that uses a sorted keyset.
-// A trie would be more efficient, but KeysetIndex mirrors the range queries of boltdb which could be dropped in if the entire index won't fit in memory.
+// QuadtreeIndex is more efficient for write heavy workloads, but KeysetIndex mirrors the range queries of DiskIndex, which can be used if the entire index won't fit in memory.
 // KeysetIndex is thread safe
 type KeysetIndex struct {
 	// Implementation uses a sorted keyset.
 	// A trie would be more efficient, but
 	sorted bool
 	keys   []qkey
-	values [][]interface{}
+	// Codec encodes values for WriteTo & ReadFrom, GobCodec is used if it's nil
+	Codec Codec
 	sync.RWMutex
 }
 
@@ -46,10 +54,13 @@ func (idx *KeysetIndex) TileRange(zmin, zmax int) <-chan Tile {
 		defer close(tiles)
 		idx.RLock()
 		defer idx.RUnlock()
+		if len(idx.keys) == 0 {
+			return
+		}
 		for i := 0; i < len(idx.keys)-1; i++ {
 			qmax := idx.keys[i].qk.Level()
 			for z := zmin; z <= zmax && z <= qmax; z++ {
-				q := idx.keys[i].qk.Parent(z)
+				q := idx.keys[i].qk.Ancestor(z)
 				n := idx.keys[i+1].qk
 				if !n.HasParent(q) {
 					tiles <- q.ToTile()
@@ -57,8 +68,8 @@ func (idx *KeysetIndex) TileRange(zmin, zmax int) <-chan Tile {
 			}
 		}
 		q := idx.keys[len(idx.keys)-1].qk
-		for z := zmin; z <= zmax && z <= len(q); z++ {
-			tiles <- q.Parent(z).ToTile()
+		for z := zmin; z <= zmax && z <= q.Level(); z++ {
+			tiles <- q.Ancestor(z).ToTile()
 		}
 	}()
 	return tiles
@@ -69,14 +80,9 @@ func (idx *KeysetIndex) Values(t Tile) (vals []interface{}) {
 	idx.sort()
 	idx.RLock()
 	defer idx.RUnlock()
-	qk := t.Quadkey()
-	i := idx.search(qk)
-	for i < len(idx.keys) {
-		n := idx.keys[i]
-		if n.qk == qk || n.qk.HasParent(qk) {
-			vals = append(vals, idx.values[n.v]...)
-		}
-		i++
+	lo, hi := idx.span(t.Quadint(), true)
+	for _, k := range idx.keys[lo:hi] {
+		vals = append(vals, k.vals...)
 	}
 	return
 }
@@ -85,29 +91,101 @@ func (idx *KeysetIndex) Values(t Tile) (vals []interface{}) {
 func (idx *KeysetIndex) Add(t Tile, val ...interface{}) {
 	idx.Lock()
 	defer idx.Unlock()
-	idx.values = append(idx.values, val)
-	qk := qkey{qk: t.Quadkey(), v: len(idx.values) - 1}
-	idx.keys = append(idx.keys, qk)
+	idx.keys = append(idx.keys, qkey{qk: t.Quadint(), vals: val})
 	idx.sorted = false
 }
 
+// Remove removes the values aggregated under the tile that match and returns how many were removed
+func (idx *KeysetIndex) Remove(t Tile, match func(interface{}) bool) (n int) {
+	idx.Lock()
+	defer idx.Unlock()
+	idx.sortKeys()
+	lo, hi := idx.span(t.Quadint(), true)
+	keys := idx.keys[:lo]
+	for _, k := range idx.keys[lo:hi] {
+		var removed int
+		k.vals, removed = removeValues(k.vals, match)
+		n += removed
+		if len(k.vals) > 0 {
+			keys = append(keys, k)
+		}
+	}
+	idx.keys = append(keys, idx.keys[hi:]...)
+	return
+}
+
+// Delete removes all values of the tile, and of its descendants if requested, and returns how many were removed
+func (idx *KeysetIndex) Delete(t Tile, descendants bool) int {
+	idx.Lock()
+	defer idx.Unlock()
+	idx.sortKeys()
+	return idx.delete(t.Quadint(), descendants)
+}
+
+// Replace sets the values of the tile, removing any it had. Replacing with no values deletes the tile.
+func (idx *KeysetIndex) Replace(t Tile, val ...interface{}) {
+	idx.Lock()
+	defer idx.Unlock()
+	idx.sortKeys()
+	qk := t.Quadint()
+	idx.delete(qk, false)
+	if len(val) == 0 {
+		return
+	}
+	// insert in place to keep the keys sorted
+	i := idx.search(qk)
+	idx.keys = append(idx.keys, qkey{})
+	copy(idx.keys[i+1:], idx.keys[i:])
+	idx.keys[i] = qkey{qk: qk, vals: val}
+}
+
+// delete removes the keys of the tile and returns the number of values they had, assumes the write lock is held & keys are sorted
+func (idx *KeysetIndex) delete(qk Quadint, descendants bool) (n int) {
+	lo, hi := idx.span(qk, descendants)
+	for _, k := range idx.keys[lo:hi] {
+		n += len(k.vals)
+	}
+	idx.keys = append(idx.keys[:lo], idx.keys[hi:]...)
+	return
+}
+
 // sorts the tiles, nothing happens if the sorted flag is set
 func (idx *KeysetIndex) sort() {
 	if !idx.sorted {
 		idx.Lock()
-		sort.Sort(byQk(idx.keys))
-		idx.sorted = true
+		idx.sortKeys()
 		idx.Unlock()
 	}
 }
 
-func (idx *KeysetIndex) search(qk Quadkey) int {
+// sortKeys is sort for callers that already hold the write lock
+func (idx *KeysetIndex) sortKeys() {
+	if !idx.sorted {
+		sort.Stable(byQk(idx.keys))
+		idx.sorted = true
+	}
+}
+
+func (idx *KeysetIndex) search(qk Quadint) int {
 	return sort.Search(len(idx.keys), func(i int) bool { return idx.keys[i].qk >= qk })
 }
 
+// span returns the range of sorted keys that are the tile, or the tile and its descendants
+func (idx *KeysetIndex) span(qk Quadint, descendants bool) (lo, hi int) {
+	lo = idx.search(qk)
+	for hi = lo; hi < len(idx.keys); hi++ {
+		n := idx.keys[hi].qk
+		if n != qk && !(descendants && n.HasParent(qk)) {
+			// descendants are contiguous in the sorted keys
+			break
+		}
+	}
+	return
+}
+
 type qkey struct {
-	qk Quadkey
-	v  int
+	qk   Quadint
+	vals []interface{}
 }
 
 type byQk []qkey
@@ -122,13 +200,15 @@ type SuffixIndex struct {
 	// \x00 joined string of keys for suffixarray
 	indexed []byte
 	index   *suffixarray.Index
-	tiles   map[Quadkey][]interface{}
+	tiles   map[Quadint][]interface{}
+	// Codec encodes values for WriteTo & ReadFrom, GobCodec is used if it's nil
+	Codec Codec
 }
 
 //NewSuffixIndex returns a new SuffixIndex
 func NewSuffixIndex() *SuffixIndex {
 	return &SuffixIndex{
-		tiles: make(map[Quadkey][]interface{}),
+		tiles: make(map[Quadint][]interface{}),
 	}
 }
 
@@ -141,7 +221,7 @@ func (idx *SuffixIndex) TileRange(zmin, zmax int) <-chan Tile {
 		seen := make(map[Tile]struct{}, len(idx.tiles)*(zmax-zmin+1))
 		for k := range idx.tiles {
 			for z := zmin; z <= zmax; z++ {
-				t := k[:z].ToTile()
+				t := k.Ancestor(z).ToTile()
 				if _, ok := seen[t]; !ok {
 					tiles <- t
 					seen[t] = struct{}{}
@@ -154,12 +234,7 @@ func (idx *SuffixIndex) TileRange(zmin, zmax int) <-chan Tile {
 
 //Values returns all the values aggregated under the given tile
 func (idx *SuffixIndex) Values(t Tile) (vals []interface{}) {
-	idx.sort()
-	qk := t.Quadkey()
-	keys := prefixes(idx.index, idx.indexed, []byte(qk))
-	for _, k := range keys {
-		qk := Quadkey(k)
-		//fmt.Println(qk)
+	for _, qk := range idx.keys(t, true) {
 		vals = append(vals, idx.tiles[qk]...)
 	}
 	return
@@ -169,16 +244,85 @@ func (idx *SuffixIndex) Values(t Tile) (vals []interface{}) {
 func (idx *SuffixIndex) Add(t Tile, v ...interface{}) {
 	// Set index to nil b/c adding invalidates index
 	idx.index = nil
-	qk := t.Quadkey()
+	qk := t.Quadint()
 	idx.tiles[qk] = append(idx.tiles[qk], v...)
 }
 
+//Remove removes the values aggregated under the tile that match and returns how many were removed
+func (idx *SuffixIndex) Remove(t Tile, match func(interface{}) bool) (n int) {
+	for _, qk := range idx.keys(t, true) {
+		vals, removed := removeValues(idx.tiles[qk], match)
+		n += removed
+		if len(vals) == 0 {
+			idx.delete(qk)
+		} else {
+			idx.tiles[qk] = vals
+		}
+	}
+	return
+}
+
+//Delete removes all values of the tile, and of its descendants if requested, and returns how many were removed
+func (idx *SuffixIndex) Delete(t Tile, descendants bool) (n int) {
+	for _, qk := range idx.keys(t, descendants) {
+		n += len(idx.tiles[qk])
+		idx.delete(qk)
+	}
+	return
+}
+
+//Replace sets the values of the tile, removing any it had. Replacing with no values deletes the tile.
+func (idx *SuffixIndex) Replace(t Tile, v ...interface{}) {
+	qk := t.Quadint()
+	if len(v) == 0 {
+		idx.delete(qk)
+		return
+	}
+	if _, ok := idx.tiles[qk]; !ok {
+		idx.index = nil
+	}
+	// copy so later Adds can't append into the caller's slice
+	idx.tiles[qk] = append([]interface{}(nil), v...)
+}
+
+//keys returns the indexed keys of the tile, and of its descendants if requested
+func (idx *SuffixIndex) keys(t Tile, descendants bool) (keys []Quadint) {
+	qk := t.Quadint()
+	switch {
+	case !descendants:
+		if _, ok := idx.tiles[qk]; ok {
+			keys = append(keys, qk)
+		}
+	case t.Z == 0:
+		// every key is under the root, which the suffixarray can't look up
+		for k := range idx.tiles {
+			keys = append(keys, k)
+		}
+	default:
+		idx.sort()
+		for _, k := range prefixes(idx.index, idx.indexed, []byte(t.Quadkey())) {
+			qk, _ := Quadkey(k).Quadint()
+			keys = append(keys, qk)
+		}
+	}
+	return
+}
+
+//delete removes the tile from the index
+func (idx *SuffixIndex) delete(qk Quadint) {
+	if _, ok := idx.tiles[qk]; ok {
+		// removing keys invalidates the index too
+		idx.index = nil
+		delete(idx.tiles, qk)
+	}
+}
+
 func (idx *SuffixIndex) sort() {
 	if idx.index == nil {
 		keys := make([][]byte, len(idx.tiles))
 		i := 0
 		for k := range idx.tiles {
-			keys[i] = []byte(k)
+			keys[i] = []byte(k.Quadkey())
 			i++
 		}
 		d := []byte{zero}
@@ -188,6 +332,18 @@ func (idx *SuffixIndex) sort() {
 	}
 }
 
+//removeValues returns the values that don't match in a new slice and how many did
+func removeValues(vals []interface{}, match func(interface{}) bool) (kept []interface{}, n int) {
+	for _, v := range vals {
+		if match(v) {
+			n++
+		} else {
+			kept = append(kept, v)
+		}
+	}
+	return
+}
+
 //prefixes assumes a \x00 delimited data with \x00 padding
 func prefixes(idx *suffixarray.Index, data, q []byte) (keys [][]byte) {
 	for _, i := range idx.Lookup(q, -1) {
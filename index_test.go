@@ -42,11 +42,58 @@ func TestTileIndex(t *testing.T) {
 func TestKeysetIndex(t *testing.T) {
 	idx := &KeysetIndex{}
 	testIndex(t, TileIndex(idx))
+	testIndexUpdates(t, &KeysetIndex{})
 }
 
 func TestSuffixIndex(t *testing.T) {
 	idx := NewSuffixIndex()
 	testIndex(t, TileIndex(idx))
+	testIndexUpdates(t, NewSuffixIndex())
+}
+
+func TestQuadtreeIndex(t *testing.T) {
+	testIndex(t, NewQuadtreeIndex())
+	testIndexUpdates(t, &QuadtreeIndex{})
+	idx := NewQuadtreeIndex()
+	for i, qk := range []string{"0000", "0001", "0010", "0011", "0100", "0101", "1111"} {
+		tile, _ := FromQuadkeyString(qk)
+		idx.Add(tile, i)
+	}
+	c := 0
+	for range idx.TileRange(1, 2) {
+		c++
+	}
+	if c != 5 {
+		t.Error("TileRange should generate 5 tiles, got ", c)
+	}
+	countTests := []struct {
+		qk    string
+		count int
+	}{
+		{"", 7},
+		{"0", 6},
+		{"00", 4},
+		{"0101", 1},
+		{"2", 0},
+		{"01010", 0},
+	}
+	for _, test := range countTests {
+		tile, _ := FromQuadkeyString(test.qk)
+		if n := idx.Count(tile); n != test.count {
+			t.Errorf("QuadtreeIndex.Count(%q) -> %d not %d", test.qk, n, test.count)
+		}
+	}
+	if vals := idx.Values(Tile{}); fmt.Sprint(vals) != "[0 1 2 3 4 5 6]" {
+		t.Errorf("QuadtreeIndex.Values should be in quadkey order, got %v", vals)
+	}
+}
+
+func TestEmptyTileRange(t *testing.T) {
+	for _, idx := range []TileIndex{&KeysetIndex{}, NewSuffixIndex(), NewQuadtreeIndex()} {
+		for tile := range idx.TileRange(0, ZMax) {
+			t.Errorf("%T.TileRange on an empty index -> %v", idx, tile)
+		}
+	}
 }
 
 func TestPrefixes(t *testing.T) {
@@ -87,6 +134,59 @@ func testIndex(t *testing.T, idx TileIndex) {
 	}
 }
 
+func testIndexUpdates(t *testing.T, idx TileIndex) {
+	esb := FromCoordinate(40.7484, -73.9857, 18)
+	sol := FromCoordinate(40.6892, -74.0445, 18)
+	bbn := FromCoordinate(51.5007, -0.1246, 18)
+	nyc := Tile{X: 75, Y: 96, Z: 8}
+	root := Tile{}
+	idx.Add(esb, "EmpireStateBuilding", "Bus1")
+	idx.Add(sol, "StatueOfLiberty", "Bus2")
+	idx.Add(bbn, "BigBen", "Bus3")
+	idx.Add(nyc, "NewYork")
+	errf := "%T.%s -> %v"
+	isBus := func(v interface{}) bool { return v.(string)[:3] == "Bus" }
+	if n := idx.Remove(nyc, isBus); n != 2 {
+		t.Errorf(errf, idx, "Remove(nyc, isBus)", n)
+	}
+	if vals := idx.Values(root); len(vals) != 5 {
+		t.Errorf(errf, idx, "Values(root) after Remove", vals)
+	}
+	if n := idx.Remove(esb, func(interface{}) bool { return true }); n != 1 {
+		t.Errorf(errf, idx, "Remove(esb, all)", n)
+	}
+	if vals := idx.Values(esb); len(vals) != 0 {
+		t.Errorf(errf, idx, "Values(esb) after Remove", vals)
+	}
+	idx.Replace(sol, "Ferry")
+	if vals := idx.Values(sol); len(vals) != 1 || vals[0] != "Ferry" {
+		t.Errorf(errf, idx, "Values(sol) after Replace", vals)
+	}
+	idx.Replace(esb, "Bus1")
+	if vals := idx.Values(nyc); len(vals) != 3 {
+		t.Errorf(errf, idx, "Values(nyc) after Replace", vals)
+	}
+	if n := idx.Delete(nyc, false); n != 1 {
+		t.Errorf(errf, idx, "Delete(nyc, false)", n)
+	}
+	if vals := idx.Values(nyc); len(vals) != 2 {
+		t.Errorf(errf, idx, "Values(nyc) after Delete", vals)
+	}
+	if n := idx.Delete(nyc, true); n != 2 {
+		t.Errorf(errf, idx, "Delete(nyc, true)", n)
+	}
+	if vals := idx.Values(root); len(vals) != 2 || vals[0] != "BigBen" {
+		t.Errorf(errf, idx, "Values(root) after Delete", vals)
+	}
+	idx.Replace(bbn)
+	if vals := idx.Values(root); len(vals) != 0 {
+		t.Errorf(errf, idx, "Values(root) after empty Replace", vals)
+	}
+	for tile := range idx.TileRange(0, ZMax) {
+		t.Errorf(errf, idx, "TileRange after deleting everything", tile)
+	}
+}
+
 var bV []interface{}
 
 func BenchmarkKeysetValues(b *testing.B) {
@@ -111,6 +211,36 @@ func BenchmarkSuffixValues(b *testing.B) {
 	}
 }
 
+func BenchmarkQuadtreeValues(b *testing.B) {
+	idx := NewQuadtreeIndex()
+	hydrateIndex(idx)
+	esb := Tile{X: 9649, Y: 12315, Z: 15}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		bV = idx.Values(esb)
+	}
+}
+
+func BenchmarkKeysetAddValues(b *testing.B) {
+	benchmarkAddValues(b, &KeysetIndex{})
+}
+
+func BenchmarkQuadtreeAddValues(b *testing.B) {
+	benchmarkAddValues(b, NewQuadtreeIndex())
+}
+
+// benchmarkAddValues interleaves writes & reads like a live layer
+func benchmarkAddValues(b *testing.B, idx TileIndex) {
+	hydrateIndex(idx)
+	esb := Tile{X: 9649, Y: 12315, Z: 15}
+	t := FromCoordinate(40.7484, -73.9857, 18)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		idx.Add(t, n)
+		bV = idx.Values(esb)
+	}
+}
+
 func hydrateIndex(idx TileIndex) {
 	mlat, mlon := 40.7, -73.9
 	for i := 0; i < 10000; i++ {
@@ -0,0 +1,85 @@
+package tiles
+
+import (
+	"fmt"
+	"math"
+)
+
+// MercatorExtent is half of the width of the Web Mercator projection in meters.
+// The projected map covers [-MercatorExtent, MercatorExtent] on both axes.
+const MercatorExtent = math.Pi * EarthRadiusM
+
+// Mercator is a Web Mercator (EPSG:3857) coordinate in meters with the origin at (0, 0) lat/lon
+type Mercator struct {
+	X, Y float64
+}
+
+// Extent is a projected bounding box in the units of its CRS
+type Extent struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+// ToMercator projects the coord to Web Mercator meters.
+// Latitude is clipped to Min/MaxLat so the result stays finite.
+func (c Coordinate) ToMercator() Mercator {
+	lat := clip(c.Lat, MinLat, MaxLat)
+	return Mercator{
+		X: EarthRadiusM * c.Lon * math.Pi / 180,
+		Y: EarthRadiusM * math.Log(math.Tan(math.Pi/4+lat*math.Pi/360)),
+	}
+}
+
+// ToCoords converts to WGS84 coordinates
+func (m Mercator) ToCoords() Coordinate {
+	return Coordinate{
+		Lat: (2*math.Atan(math.Exp(m.Y/EarthRadiusM)) - math.Pi/2) * 180 / math.Pi,
+		Lon: m.X / EarthRadiusM * 180 / math.Pi,
+	}
+}
+
+// ToPixel gets the Pixel of the point at the zoom level
+func (m Mercator) ToPixel(zoom int) Pixel {
+	return defaultGrid().MercatorToPixel(m, zoom)
+}
+
+// ToWorld normalizes the point to a WorldPoint
+func (m Mercator) ToWorld() WorldPoint {
+	return WorldPoint{
+		X: (m.X + MercatorExtent) / (2 * MercatorExtent),
+		Y: (MercatorExtent - m.Y) / (2 * MercatorExtent),
+	}
+}
+
+// ToTile gets the tile that contains the point at the zoom level
+func (m Mercator) ToTile(zoom int) Tile {
+	return defaultGrid().MercatorToTile(m, zoom)
+}
+
+func (m Mercator) String() string {
+	return fmt.Sprintf("(%v, %v)", m.X, m.Y)
+}
+
+// ToMercator converts the point to Web Mercator meters
+func (w WorldPoint) ToMercator() Mercator {
+	return Mercator{
+		X: w.X*2*MercatorExtent - MercatorExtent,
+		Y: MercatorExtent - w.Y*2*MercatorExtent,
+	}
+}
+
+// ToMercator converts the NW corner of the pixel to Web Mercator meters
+func (p Pixel) ToMercator() Mercator {
+	return defaultGrid().PixelToMercator(p)
+}
+
+// BoundsMercator returns the Web Mercator extent of this tile in meters
+func (t Tile) BoundsMercator() Extent {
+	size := float64(uint64(1) << uint(t.Z))
+	span := 2 * MercatorExtent / size
+	return Extent{
+		MinX: float64(t.X)*span - MercatorExtent,
+		MinY: MercatorExtent - float64(t.Y+1)*span,
+		MaxX: float64(t.X+1)*span - MercatorExtent,
+		MaxY: MercatorExtent - float64(t.Y)*span,
+	}
+}
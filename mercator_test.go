@@ -0,0 +1,68 @@
+package tiles
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCoordinateToMercator(t *testing.T) {
+	mercTests := []struct {
+		coords Coordinate
+		merc   Mercator
+	}{
+		{Coordinate{0, 0}, Mercator{0, 0}},
+		{Coordinate{0, 180}, Mercator{MercatorExtent, 0}},
+		{Coordinate{MaxLat, -180}, Mercator{-MercatorExtent, MercatorExtent}},
+		{Coordinate{40.7484, -73.9857}, Mercator{-8236050.4500, 4975301.2538}},
+	}
+	errf := "Coordinate%+v.ToMercator() -> %+v"
+	for _, test := range mercTests {
+		merc := test.coords.ToMercator()
+		if math.Abs(merc.X-test.merc.X) > 1e-3 || math.Abs(merc.Y-test.merc.Y) > 1e-3 {
+			t.Errorf(errf, test.coords, merc)
+		}
+		if coords := merc.ToCoords(); !coords.Equals(test.coords) {
+			t.Errorf("Mercator%+v.ToCoords() -> %+v", merc, coords)
+		}
+	}
+}
+
+func TestMercatorToTile(t *testing.T) {
+	esb := Coordinate{40.7484, -73.9857}
+	tile := FromCoordinate(esb.Lat, esb.Lon, 18)
+	if mt := esb.ToMercator().ToTile(18); mt != tile {
+		t.Errorf("Mercator.ToTile(18) -> %+v not %+v", mt, tile)
+	}
+	p := esb.ToPixel(18)
+	if mp := esb.ToMercator().ToPixel(18); mp != p {
+		t.Errorf("Mercator.ToPixel(18) -> %+v not %+v", mp, p)
+	}
+}
+
+func TestTileBoundsMercator(t *testing.T) {
+	tileTests := []struct {
+		tile   Tile
+		extent Extent
+	}{
+		{Tile{0, 0, 0}, Extent{-MercatorExtent, -MercatorExtent, MercatorExtent, MercatorExtent}},
+		{Tile{1, 0, 1}, Extent{0, 0, MercatorExtent, MercatorExtent}},
+	}
+	errf := "Tile%+v.BoundsMercator() -> %+v"
+	for _, test := range tileTests {
+		extent := test.tile.BoundsMercator()
+		if extent != test.extent {
+			t.Errorf(errf, test.tile, extent)
+		}
+	}
+	tile := Tile{X: 26, Y: 48, Z: 7}
+	e := tile.BoundsMercator()
+	nw := tile.ToPixel().ToMercator()
+	if !floatEquals(nw.X, e.MinX) || !floatEquals(nw.Y, e.MaxY) {
+		t.Errorf("Tile%+v.BoundsMercator() %+v does not match NW pixel %+v", tile, e, nw)
+	}
+	b := tile.Bounds()
+	sw := Mercator{e.MinX, e.MinY}.ToCoords()
+	if !sw.Equals(b.SW()) {
+		t.Errorf("Tile%+v.BoundsMercator() SW %+v does not match Bounds() %+v", tile, sw, b.SW())
+	}
+}
@@ -1,6 +1,7 @@
 package tiles
 
 import (
+	"math"
 	"testing"
 )
 
@@ -22,20 +23,48 @@ func TestPixelToCoords(t *testing.T) {
 
 func TestPixelToTile(t *testing.T) {
 	coordTests := []struct {
-		pixel Pixel
-		tile  Tile
+		pixel  Pixel
+		tile   Tile
+		tpixel TilePixel
 	}{
-		{Pixel{6827, 12405, 7}, Tile{26, 48, 7}},
+		{Pixel{6827, 12405, 7}, Tile{26, 48, 7}, TilePixel{X: 171, Y: 117}},
 	}
 	errf := "Pixel%+v: %+v -> %+v"
 	for _, test := range coordTests {
 		tile, tpixel := test.pixel.ToTile()
-		_ = tpixel
-		// t.Logf("%+v -> %+v %+v", test.pixel, tile, tpixel)
-		// TODO test tpixel
-		// pixel_test.go:33: {X:6827 Y:12405 Z:7} -> {X:26 Y:48 Z:7} {X:171 Y:117 Tile:0xc20801e040}
 		if tile != test.tile {
 			t.Errorf(errf, test.pixel, test.tile, tile)
 		}
+		if tpixel.X != test.tpixel.X || tpixel.Y != test.tpixel.Y || *tpixel.Tile != tile {
+			t.Errorf(errf, test.pixel, test.tpixel, tpixel)
+		}
+	}
+}
+
+func TestTilePixel(t *testing.T) {
+	c := Coordinate{40.0, -105.0}
+	tp := c.ToTilePixel(7)
+	// floored, so one less than the rounded c.ToPixel(7)
+	floored := Pixel{6826, 12405, 7}
+	if tp.X != 170 || tp.Y != 117 || *tp.Tile != (Tile{26, 48, 7}) {
+		t.Errorf("Coordinate%+v.ToTilePixel(7) -> %+v %+v", c, tp, *tp.Tile)
+	}
+	if p := tp.ToPixel(); p != floored {
+		t.Errorf("TilePixel%+v.ToPixel() -> %+v", tp, p)
+	}
+	if tc := tp.ToCoords(); !tc.Equals(floored.ToCoords()) {
+		t.Errorf("TilePixel%+v.ToCoords() -> %+v", tp, tc)
+	}
+	// within a pixel of the original coordinate
+	fp, tfp := c.ToFloatPixel(7), tp.ToCoords().ToFloatPixel(7)
+	if math.Abs(fp.X-tfp.X) > 1 || math.Abs(fp.Y-tfp.Y) > 1 {
+		t.Errorf("TilePixel%+v.ToCoords() -> %+v is too far from %+v", tp, tfp, fp)
+	}
+	// near the east & south edges the tile is the same as ToTile
+	b := tp.Tile.Bounds()
+	for _, edge := range []Coordinate{{Lat: 40, Lon: b.East - 0.0005}, {Lat: b.South + 0.0005, Lon: b.West}} {
+		if tp, tile := edge.ToTilePixel(7), edge.ToTile(7); *tp.Tile != tile {
+			t.Errorf("Coordinate%+v.ToTilePixel(7) -> %v not %v", edge, *tp.Tile, tile)
+		}
 	}
 }
@@ -0,0 +1,118 @@
+package tiles
+
+import (
+	"errors"
+)
+
+const (
+	quadintLevelBits = 5
+	quadintLevelMask = 1<<quadintLevelBits - 1
+)
+
+// Quadint is a quadkey packed into a uint64 that doesn't allocate like the string Quadkey.
+// The level is stored in the low 5 bits and the quadkey digits are interleaved Morton bits starting at the high bit,
+// so ordering Quadints matches the prefix ordering of their Quadkey strings.
+type Quadint uint64
+
+// Quadint returns the packed quadkey of the tile.
+// The result is invalid if the tile is invalid
+func (t Tile) Quadint() Quadint {
+	var q uint64
+	for i := t.Z; i > 0; i-- {
+		m := 1 << uint(i-1)
+		var d uint64
+		if (t.X & m) != 0 {
+			d++
+		}
+		if (t.Y & m) != 0 {
+			d += 2
+		}
+		q |= d << digitShift(t.Z-i)
+	}
+	return Quadint(q | uint64(t.Z))
+}
+
+// Quadint returns the packed version of this quadkey.
+// Returns an error if the quadkey is invalid
+func (q Quadkey) Quadint() (Quadint, error) {
+	if len(q) > ZMax {
+		return 0, errors.New("Invalid Quadkey " + string(q))
+	}
+	var qi uint64
+	for i := 0; i < len(q); i++ {
+		d := q[i] - '0'
+		if d > 3 {
+			return 0, errors.New("Invalid Quadkey " + string(q))
+		}
+		qi |= uint64(d) << digitShift(i)
+	}
+	return Quadint(qi | uint64(len(q))), nil
+}
+
+// Level returns the depth of the quadint in the tree structure
+func (q Quadint) Level() int {
+	return int(q & quadintLevelMask)
+}
+
+// ToTile returns the Tile represented by this Quadint
+func (q Quadint) ToTile() (tile Tile) {
+	tile.Z = q.Level()
+	for i := 0; i < tile.Z; i++ {
+		m := 1 << uint(tile.Z-i-1)
+		d := q.digit(i)
+		if d&1 != 0 {
+			tile.X |= m
+		}
+		if d&2 != 0 {
+			tile.Y |= m
+		}
+	}
+	return
+}
+
+// Quadkey returns the string Quadkey of this Quadint
+func (q Quadint) Quadkey() Quadkey {
+	var qk [ZMax]byte
+	z := q.Level()
+	for i := 0; i < z; i++ {
+		qk[i] = '0' + byte(q.digit(i))
+	}
+	return Quadkey(qk[:z])
+}
+
+// Ancestor returns the ancestor of the quadint at the given level z.
+// Levels below 0 return the root and levels beyond q.Level() return q.
+func (q Quadint) Ancestor(z int) Quadint {
+	switch {
+	case z < 0:
+		return 0
+	case z > q.Level():
+		return q
+	}
+	return Quadint(uint64(q)&^quadintLevelMask&prefixMask(z) | uint64(z))
+}
+
+// HasParent returns a true if o is a parent of q.
+// If q == o, it return false
+func (q Quadint) HasParent(o Quadint) bool {
+	z := o.Level()
+	return q.Level() > z && q.Ancestor(z) == o
+}
+
+// digit returns the ith quadkey digit
+func (q Quadint) digit(i int) uint64 {
+	return uint64(q) >> digitShift(i) & 3
+}
+
+// digitShift is the bit offset of the ith quadkey digit
+func digitShift(i int) uint {
+	return uint(62 - 2*i)
+}
+
+// prefixMask masks the digits of the first z levels
+func prefixMask(z int) uint64 {
+	if z == 0 {
+		return 0
+	}
+	return ^uint64(0) << digitShift(z-1)
+}
@@ -0,0 +1,114 @@
+package tiles
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestQuadintTile(t *testing.T) {
+	tests := []Tile{
+		{X: 0, Y: 0, Z: 0},
+		{X: 1, Y: 0, Z: 1},
+		{X: 26, Y: 48, Z: 7},
+		{X: 77197, Y: 98526, Z: 18},
+		{X: 1<<ZMax - 1, Y: 1<<ZMax - 1, Z: ZMax},
+	}
+	errf := "Tile%+v.Quadint().ToTile() -> %+v"
+	for _, test := range tests {
+		q := test.Quadint()
+		if tile := q.ToTile(); tile != test {
+			t.Errorf(errf, test, tile)
+		}
+		if qk := q.Quadkey(); qk != test.Quadkey() {
+			t.Errorf("Tile%+v.Quadint().Quadkey() -> %q", test, qk)
+		}
+		if q.Level() != test.Z {
+			t.Errorf("Tile%+v.Quadint().Level() -> %d", test, q.Level())
+		}
+	}
+}
+
+func TestQuadkeyQuadint(t *testing.T) {
+	tests := []struct {
+		qk  Quadkey
+		err bool
+	}{
+		{"", false},
+		{"0231010", false},
+		{"0231410", true},
+		{"01230123012301230123012", false},
+		{"012301230123012301230123", true},
+	}
+	for _, test := range tests {
+		q, err := test.qk.Quadint()
+		if (err != nil) != test.err {
+			t.Errorf("Quadkey(%q).Quadint() error -> %v", test.qk, err)
+		}
+		if err == nil && q.Quadkey() != test.qk {
+			t.Errorf("Quadkey(%q).Quadint().Quadkey() -> %q", test.qk, q.Quadkey())
+		}
+	}
+}
+
+func TestQuadintOrder(t *testing.T) {
+	qks := []Quadkey{"1", "", "01", "0", "3", "00", "0123", "012", "2", "013", "0000"}
+	qis := make([]Quadint, len(qks))
+	for i, qk := range qks {
+		qis[i], _ = qk.Quadint()
+	}
+	sort.Sort(quadkeys(qks))
+	sort.Slice(qis, func(i, j int) bool { return qis[i] < qis[j] })
+	for i := range qks {
+		if qis[i].Quadkey() != qks[i] {
+			t.Errorf("Quadint order %d -> %q, want %q", i, qis[i].Quadkey(), qks[i])
+		}
+	}
+}
+
+func TestQuadintAncestor(t *testing.T) {
+	tests := []struct {
+		q Quadkey
+		z int
+		p Quadkey
+	}{
+		{"", 0, ""},
+		{"0", 0, ""},
+		{"012", 1, "0"},
+		{"312", 2, "31"},
+		{"012", 3, "012"},
+		{"012", -1, ""},
+		{"012", 5, "012"},
+	}
+	errf := "Quadint(%q).Ancestor(%d) -> %q"
+	for _, test := range tests {
+		q, _ := test.q.Quadint()
+		p := q.Ancestor(test.z).Quadkey()
+		if p != test.p {
+			t.Errorf(errf, test.q, test.z, p)
+		}
+	}
+}
+
+func TestQuadintHasParent(t *testing.T) {
+	tests := []struct {
+		q Quadkey
+		p Quadkey
+		t bool
+	}{
+		{"", "", false},
+		{"0", "001", false},
+		{"012", "0", true},
+		{"0", "0", false},
+		{"012", "", true},
+		{"112", "0", false},
+		{"012123123", "012", true},
+	}
+	errf := "Quadint(%q).HasParent(%q) -> %+v"
+	for _, test := range tests {
+		q, _ := test.q.Quadint()
+		p, _ := test.p.Quadint()
+		if q.HasParent(p) != test.t {
+			t.Errorf(errf, test.q, test.p, !test.t)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 package tiles
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
 // Quadkey represents a Bing Maps quadkey
 // It can also be used as a quadtree data structure
 type Quadkey string
@@ -14,12 +20,65 @@ func (q Quadkey) HasParent(o Quadkey) bool {
 	return q[:z] == o
 }
 
-// Parent returns the parent of the object at the given level z.
-// If level invalid (<0 || > q.Level()) it panics
-func (q Quadkey) Parent(z int) Quadkey {
+// IsAncestorOf returns true if q is an ancestor of o.
+// If q == o, it returns false
+func (q Quadkey) IsAncestorOf(o Quadkey) bool {
+	return o.HasParent(q)
+}
+
+// Parent returns the quadkey one level above this one.
+// The root quadkey "" is its own parent.
+func (q Quadkey) Parent() Quadkey {
+	if len(q) == 0 {
+		return q
+	}
+	return q[:len(q)-1]
+}
+
+// ParentChecked returns the quadkey one level above this one or an error if q is the root or invalid
+func (q Quadkey) ParentChecked() (Quadkey, error) {
+	if err := q.Validate(); err != nil {
+		return "", err
+	}
+	if len(q) == 0 {
+		return "", errors.New("Root Quadkey has no parent")
+	}
+	return q.Parent(), nil
+}
+
+// Ancestor returns the ancestor of the quadkey at the given level z.
+// Levels below 0 return the root and levels beyond q.Level() return q.
+func (q Quadkey) Ancestor(z int) Quadkey {
+	switch {
+	case z < 0:
+		return ""
+	case z > len(q):
+		return q
+	}
 	return q[:z]
 }
 
+// AncestorChecked returns the ancestor of the quadkey at the given level z.
+// Returns an error if q is invalid or z is outside of [0, q.Level()]
+func (q Quadkey) AncestorChecked(z int) (Quadkey, error) {
+	if err := q.Validate(); err != nil {
+		return "", err
+	}
+	if z < 0 || z > len(q) {
+		return "", fmt.Errorf("Invalid level %d for Quadkey %q: must be in [0, %d]", z, q, len(q))
+	}
+	return q[:z], nil
+}
+
+// CommonAncestor returns the deepest quadkey that is a parent of or equal to both a and b.
+func CommonAncestor(a, b Quadkey) Quadkey {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return a[:i]
+}
+
 // Level returns the depth of the quadkey in the tree structure
 func (q Quadkey) Level() int {
 	return len(q)
@@ -35,9 +94,170 @@ func (q Quadkey) Children() []Quadkey {
 	}
 }
 
+// Child returns the ith child of this quadkey in the next level of the tree.
+// Panics if i is not in [0, 3], the same as indexing Children()
+func (q Quadkey) Child(i int) Quadkey {
+	return q.Children()[i]
+}
+
+// Siblings returns the other quadkeys that share the same parent as this one.
+// The root quadkey has no siblings.
+func (q Quadkey) Siblings() []Quadkey {
+	if len(q) == 0 {
+		return nil
+	}
+	siblings := make([]Quadkey, 0, 3)
+	for _, c := range q.Parent().Children() {
+		if c != q {
+			siblings = append(siblings, c)
+		}
+	}
+	return siblings
+}
+
+// Descendants returns a channel of all the descendants of this quadkey at level z in sorted order.
+// If z is not deeper than q.Level() the channel is closed without any values.
+func (q Quadkey) Descendants(z int) <-chan Quadkey {
+	qks := make(chan Quadkey, 1<<10)
+	go func() {
+		defer close(qks)
+		if z <= len(q) || z > ZMax {
+			return
+		}
+		var walk func(qk Quadkey)
+		walk = func(qk Quadkey) {
+			if len(qk) == z {
+				qks <- qk
+				return
+			}
+			for _, c := range qk.Children() {
+				walk(c)
+			}
+		}
+		walk(q)
+	}()
+	return qks
+}
+
+// Contains returns true if the point is inside of the tile represented by this quadkey.
+// Points on the east or south edge of the tile belong to the next tile like Bounds. Invalid quadkeys contain nothing.
+func (q Quadkey) Contains(point Coordinate) bool {
+	t, err := q.ToTileChecked()
+	return err == nil && point.containingTile(t.Z) == t
+}
+
+// Validate returns an error if the quadkey is deeper than ZMax or has a digit outside of 0-3
+func (q Quadkey) Validate() error {
+	_, err := FromQuadkeyString(string(q))
+	return err
+}
+
+// ToTileChecked returns the Tile represented by this Quadkey or an error if the quadkey is invalid
+func (q Quadkey) ToTileChecked() (Tile, error) {
+	return FromQuadkeyString(string(q))
+}
+
 // ToTile returns the Tile represented by this Quadkey
+// Panics if the quadkey is invalid, use ToTileChecked for untrusted quadkeys
 func (q Quadkey) ToTile() Tile {
 	t, err := FromQuadkeyString(string(q))
 	check(err)
 	return t
 }
+
+// Compact merges any four complete siblings into their parent, repeating up the tree until no more merges are possible.
+// Duplicates and keys that have an ancestor in the set are dropped. The result is sorted.
+// Returns an error if any of the keys is invalid
+func Compact(qks []Quadkey) ([]Quadkey, error) {
+	levels := make([]map[Quadkey]struct{}, ZMax+1)
+	for z := range levels {
+		levels[z] = make(map[Quadkey]struct{})
+	}
+	for _, qk := range qks {
+		if err := qk.Validate(); err != nil {
+			return nil, err
+		}
+		levels[qk.Level()][qk] = struct{}{}
+	}
+	for z := ZMax; z > 0; z-- {
+		for qk := range levels[z] {
+			p := qk.Parent()
+			if _, ok := levels[z-1][p]; ok {
+				continue
+			}
+			full := true
+			for _, c := range p.Children() {
+				if _, ok := levels[z][c]; !ok {
+					full = false
+					break
+				}
+			}
+			if full {
+				levels[z-1][p] = struct{}{}
+			}
+		}
+	}
+	var compacted []Quadkey
+	covered := func(qk Quadkey) bool {
+		for z := 0; z < qk.Level(); z++ {
+			if _, ok := levels[z][qk.Ancestor(z)]; ok {
+				return true
+			}
+		}
+		return false
+	}
+	for z := range levels {
+		for qk := range levels[z] {
+			if !covered(qk) {
+				compacted = append(compacted, qk)
+			}
+		}
+	}
+	sort.Sort(quadkeys(compacted))
+	return compacted, nil
+}
+
+// Uncompact expands a set of mixed level quadkeys into the keys at level z that they cover.
+// Keys deeper than z are replaced by their ancestor at z. The result is sorted and unique.
+// A key at level l expands to 4^(z-l) keys, so z should be close to the levels of the keys.
+// Returns an error if z is outside of [0, ZMax] or any of the keys is invalid
+func Uncompact(qks []Quadkey, z int) ([]Quadkey, error) {
+	if err := ValidateZoom(z); err != nil {
+		return nil, err
+	}
+	for _, qk := range qks {
+		if err := qk.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	set := make(map[Quadkey]struct{})
+	for _, qk := range qks {
+		if qk.Level() >= z {
+			set[qk.Ancestor(z)] = struct{}{}
+			continue
+		}
+		level := []Quadkey{qk}
+		for l := qk.Level(); l < z; l++ {
+			next := make([]Quadkey, 0, 4*len(level))
+			for _, q := range level {
+				next = append(next, q.Children()...)
+			}
+			level = next
+		}
+		for _, q := range level {
+			set[q] = struct{}{}
+		}
+	}
+	uncompacted := make([]Quadkey, 0, len(set))
+	for qk := range set {
+		uncompacted = append(uncompacted, qk)
+	}
+	sort.Sort(quadkeys(uncompacted))
+	return uncompacted, nil
+}
+
+type quadkeys []Quadkey
+
+func (q quadkeys) Len() int           { return len(q) }
+func (q quadkeys) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q quadkeys) Less(i, j int) bool { return q[i] < q[j] }
@@ -1,6 +1,7 @@
 package tiles
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -24,7 +25,45 @@ func TestQuadkeyHasParent(t *testing.T) {
 	}
 }
 
+func TestQuadkeyIsAncestorOf(t *testing.T) {
+	tests := []struct {
+		q Quadkey
+		o Quadkey
+		t bool
+	}{
+		{"", "", false},
+		{"", "0", true},
+		{"001", "0", false},
+		{"0", "012", true},
+		{"012", "013", false},
+	}
+	errf := "Quadkey(%q).IsAncestorOf(%q) -> %+v"
+	for _, test := range tests {
+		if test.q.IsAncestorOf(test.o) != test.t {
+			t.Errorf(errf, test.q, test.o, !test.t)
+		}
+	}
+}
+
 func TestQuadkeyParent(t *testing.T) {
+	tests := []struct {
+		q Quadkey
+		p Quadkey
+	}{
+		{"", ""},
+		{"0", ""},
+		{"012", "01"},
+	}
+	errf := "Quadkey(%q).Parent() -> %q"
+	for _, test := range tests {
+		p := test.q.Parent()
+		if p != test.p {
+			t.Errorf(errf, test.q, p)
+		}
+	}
+}
+
+func TestQuadkeyAncestor(t *testing.T) {
 	tests := []struct {
 		q Quadkey
 		z int
@@ -34,10 +73,12 @@ func TestQuadkeyParent(t *testing.T) {
 		{"0", 0, ""},
 		{"012", 1, "0"},
 		{"012", 3, "012"},
+		{"012", -1, ""},
+		{"012", 5, "012"},
 	}
-	errf := "Quadkey(%q).Parent(%d) -> %q"
+	errf := "Quadkey(%q).Ancestor(%d) -> %q"
 	for _, test := range tests {
-		p := test.q.Parent(test.z)
+		p := test.q.Ancestor(test.z)
 		if p != test.p {
 			t.Errorf(errf, test.q, test.z, p)
 		}
@@ -79,6 +120,114 @@ func TestQuadkeyChildren(t *testing.T) {
 	}
 }
 
+func TestCommonAncestor(t *testing.T) {
+	tests := []struct {
+		a, b Quadkey
+		c    Quadkey
+	}{
+		{"", "", ""},
+		{"0123", "0132", "01"},
+		{"0123", "01", "01"},
+		{"0", "1", ""},
+	}
+	errf := "CommonAncestor(%q, %q) -> %q"
+	for _, test := range tests {
+		c := CommonAncestor(test.a, test.b)
+		if c != test.c {
+			t.Errorf(errf, test.a, test.b, c)
+		}
+	}
+}
+
+func TestQuadkeyChild(t *testing.T) {
+	q := Quadkey("01")
+	for i, c := range q.Children() {
+		if q.Child(i) != c {
+			t.Errorf("Quadkey(%q).Child(%d) -> %q", q, i, q.Child(i))
+		}
+	}
+}
+
+func TestQuadkeySiblings(t *testing.T) {
+	tests := []struct {
+		q Quadkey
+		s []Quadkey
+	}{
+		{"", nil},
+		{"1", []Quadkey{"0", "2", "3"}},
+		{"023", []Quadkey{"020", "021", "022"}},
+	}
+	errf := "Quadkey(%q).Siblings() -> %+v"
+	for _, test := range tests {
+		s := test.q.Siblings()
+		if !qkSliceEqual(s, test.s) {
+			t.Errorf(errf, test.q, s)
+		}
+	}
+}
+
+func TestQuadkeyDescendants(t *testing.T) {
+	tests := []struct {
+		q Quadkey
+		z int
+		n int
+	}{
+		{"", 0, 0},
+		{"", 1, 4},
+		{"0", 3, 16},
+		{"0123", 2, 0},
+	}
+	errf := "Quadkey(%q).Descendants(%d) -> %d keys"
+	for _, test := range tests {
+		var prev Quadkey
+		n := 0
+		for d := range test.q.Descendants(test.z) {
+			if !d.HasParent(test.q) || d.Level() != test.z || d <= prev {
+				t.Errorf("Quadkey(%q).Descendants(%d) returned %q", test.q, test.z, d)
+			}
+			prev = d
+			n++
+		}
+		if n != test.n {
+			t.Errorf(errf, test.q, test.z, n)
+		}
+	}
+}
+
+func TestQuadkeyContains(t *testing.T) {
+	esb := Coordinate{Lat: 40.7484, Lon: -73.9857}
+	tests := []struct {
+		q Quadkey
+		t bool
+	}{
+		{"", true},
+		{"0320101", true},
+		{"0320102", false},
+		{FromCoordinate(esb.Lat, esb.Lon, 18).Quadkey(), true},
+	}
+	errf := "Quadkey(%q).Contains(%v) -> %+v"
+	for _, test := range tests {
+		if test.q.Contains(esb) != test.t {
+			t.Errorf(errf, test.q, esb, !test.t)
+		}
+	}
+	// within half a pixel of the east edge
+	edge := Coordinate{Lat: 40, Lon: Tile{X: 26, Y: 48, Z: 7}.Bounds().East - 0.0005}
+	edgeTests := []struct {
+		q Quadkey
+		t bool
+	}{
+		{Tile{X: 26, Y: 48, Z: 7}.Quadkey(), true},
+		{Tile{X: 27, Y: 48, Z: 7}.Quadkey(), false},
+		{"0324", false},
+	}
+	for _, test := range edgeTests {
+		if test.q.Contains(edge) != test.t {
+			t.Errorf(errf, test.q, edge, !test.t)
+		}
+	}
+}
+
 func TestQuadkeyToTile(t *testing.T) {
 	tests := []struct {
 		q Quadkey
@@ -97,6 +246,98 @@ func TestQuadkeyToTile(t *testing.T) {
 	}
 }
 
+func TestCompact(t *testing.T) {
+	tests := []struct {
+		in  []Quadkey
+		out []Quadkey
+	}{
+		{nil, nil},
+		{[]Quadkey{"0", "1", "2", "3"}, []Quadkey{""}},
+		{[]Quadkey{"00", "01", "02", "03", "1"}, []Quadkey{"0", "1"}},
+		{[]Quadkey{"000", "001", "002", "003", "01", "02", "03", "1", "2", "3"}, []Quadkey{""}},
+		{[]Quadkey{"012", "0", "012", "3"}, []Quadkey{"0", "3"}},
+		{[]Quadkey{"00", "01", "02", "2"}, []Quadkey{"00", "01", "02", "2"}},
+	}
+	errf := "Compact(%q) -> %q"
+	for _, test := range tests {
+		out, err := Compact(test.in)
+		if err != nil || !qkSliceEqual(out, test.out) {
+			t.Errorf(errf, test.in, out)
+		}
+	}
+	deep := Quadkey(strings.Repeat("0", ZMax+1))
+	for _, in := range [][]Quadkey{{"0", "4"}, {"0", deep}} {
+		if out, err := Compact(in); err == nil {
+			t.Errorf(errf, in, out)
+		}
+	}
+}
+
+func TestUncompact(t *testing.T) {
+	tests := []struct {
+		in  []Quadkey
+		z   int
+		out []Quadkey
+	}{
+		{[]Quadkey{""}, 1, []Quadkey{"0", "1", "2", "3"}},
+		{[]Quadkey{"0", "10"}, 2, []Quadkey{"00", "01", "02", "03", "10"}},
+		{[]Quadkey{"0", "012"}, 1, []Quadkey{"0"}},
+		{[]Quadkey{"0", "01"}, 2, []Quadkey{"00", "01", "02", "03"}},
+	}
+	errf := "Uncompact(%q, %d) -> %q"
+	for _, test := range tests {
+		out, err := Uncompact(test.in, test.z)
+		if err != nil || !qkSliceEqual(out, test.out) {
+			t.Errorf(errf, test.in, test.z, out)
+		}
+	}
+	invalidTests := []struct {
+		in []Quadkey
+		z  int
+	}{
+		{[]Quadkey{"0"}, -1},
+		{[]Quadkey{"0"}, ZMax + 1},
+		{[]Quadkey{"0", "x"}, 2},
+		{[]Quadkey{Quadkey(strings.Repeat("1", ZMax+1))}, 2},
+	}
+	for _, test := range invalidTests {
+		if out, err := Uncompact(test.in, test.z); err == nil {
+			t.Errorf(errf, test.in, test.z, out)
+		}
+	}
+	qks := []Quadkey{"0", "10", "11", "123", "3"}
+	uncompacted, _ := Uncompact(qks, 5)
+	out, _ := Compact(uncompacted)
+	if compacted, _ := Compact(qks); !qkSliceEqual(out, compacted) {
+		t.Errorf("Compact(Uncompact(%q)) -> %q", qks, out)
+	}
+}
+
+func TestQuadkeyChecked(t *testing.T) {
+	tests := []struct {
+		q                  Quadkey
+		tile, parent, anc1 bool
+	}{
+		{"", true, false, false},
+		{"0", true, true, true},
+		{"0231010301", true, true, true},
+		{"0241010301", false, false, false},
+		{"012301230123012301230123", false, false, false},
+	}
+	errf := "Quadkey(%q).%s -> %v"
+	for _, test := range tests {
+		if _, err := test.q.ToTileChecked(); (err == nil) != test.tile {
+			t.Errorf(errf, test.q, "ToTileChecked()", err)
+		}
+		if _, err := test.q.ParentChecked(); (err == nil) != test.parent {
+			t.Errorf(errf, test.q, "ParentChecked()", err)
+		}
+		if _, err := test.q.AncestorChecked(1); (err == nil) != test.anc1 {
+			t.Errorf(errf, test.q, "AncestorChecked(1)", err)
+		}
+	}
+}
+
 func qkSliceEqual(x, y []Quadkey) bool {
 	if len(x) != len(y) {
 		return false
@@ -0,0 +1,271 @@
+package tiles
+
+import (
+	"sync"
+)
+
+// QuadtreeIndex is a TileIndex implementation that stores values in a 4-ary tree keyed by quadkey digits.
+// Writes are O(depth) and don't invalidate anything, and each node caches the number of values under it
+// so Values allocates once and Count doesn't scan.
+// The zero value is an empty index. QuadtreeIndex is thread safe
+type QuadtreeIndex struct {
+	root qtNode
+	// agg keeps the summaries of the nodes up to date if it's set, see AggregateIndex
+	agg Aggregator
+	sync.RWMutex
+}
+
+type qtNode struct {
+	children [4]*qtNode
+	vals     []interface{}
+	// count is the number of values in this node and all of its descendants
+	count int
+	// own is the summary of vals and summary is own combined with the summaries of the children
+	own, summary interface{}
+}
+
+// NewQuadtreeIndex returns an empty QuadtreeIndex
+func NewQuadtreeIndex() *QuadtreeIndex {
+	return &QuadtreeIndex{}
+}
+
+// TileRange returns a channel of all tiles in the index in the zoom range ordered depth first by quadkey.
+// If zmax is greater than the deepest tile level, the deepest tile level returns
+// Acquires a readlock for duration of returned channel being open
+func (idx *QuadtreeIndex) TileRange(zmin, zmax int) <-chan Tile {
+	tiles := make(chan Tile, 1<<10)
+	go func() {
+		defer close(tiles)
+		idx.RLock()
+		defer idx.RUnlock()
+		var walk func(n *qtNode, t Tile)
+		walk = func(n *qtNode, t Tile) {
+			if n.count == 0 || t.Z > zmax {
+				return
+			}
+			if t.Z >= zmin {
+				tiles <- t
+			}
+			for d, c := range n.children {
+				if c != nil {
+					walk(c, Tile{X: t.X<<1 | d&1, Y: t.Y<<1 | d>>1, Z: t.Z + 1})
+				}
+			}
+		}
+		walk(&idx.root, Tile{})
+	}()
+	return tiles
+}
+
+// Values returns a list of values aggregated under the requested tile in quadkey order
+func (idx *QuadtreeIndex) Values(t Tile) (vals []interface{}) {
+	idx.RLock()
+	defer idx.RUnlock()
+	path := idx.path(t, false)
+	if path == nil {
+		return
+	}
+	n := path[len(path)-1]
+	vals = make([]interface{}, 0, n.count)
+	return n.appendValues(vals)
+}
+
+// Count returns the number of values aggregated under the requested tile
+func (idx *QuadtreeIndex) Count(t Tile) int {
+	idx.RLock()
+	defer idx.RUnlock()
+	path := idx.path(t, false)
+	if path == nil {
+		return 0
+	}
+	return path[len(path)-1].count
+}
+
+// Add adds values to the tile
+func (idx *QuadtreeIndex) Add(t Tile, val ...interface{}) {
+	if len(val) == 0 {
+		return
+	}
+	// summarize the values before touching the tree, so an aggregator that panics on a value leaves it unchanged
+	var own interface{}
+	if idx.agg != nil {
+		own = idx.agg.Identity()
+		for _, v := range val {
+			own = idx.agg.Combine(own, idx.agg.Lift(v))
+		}
+	}
+	idx.Lock()
+	defer idx.Unlock()
+	path := idx.path(t, true)
+	n := path[len(path)-1]
+	if idx.agg != nil {
+		n.own = idx.agg.Combine(n.own, own)
+	}
+	n.vals = append(n.vals, val...)
+	adjustCounts(path, len(val))
+	idx.summarize(path)
+}
+
+// Remove removes the values aggregated under the tile that match and returns how many were removed
+func (idx *QuadtreeIndex) Remove(t Tile, match func(interface{}) bool) int {
+	idx.Lock()
+	defer idx.Unlock()
+	path := idx.path(t, false)
+	if path == nil {
+		return 0
+	}
+	n := path[len(path)-1].remove(match, idx.agg)
+	adjustCounts(path, -n)
+	idx.summarize(path)
+	return n
+}
+
+// Delete removes all values of the tile, and of its descendants if requested, and returns how many were removed
+func (idx *QuadtreeIndex) Delete(t Tile, descendants bool) int {
+	idx.Lock()
+	defer idx.Unlock()
+	path := idx.path(t, false)
+	if path == nil {
+		return 0
+	}
+	node := path[len(path)-1]
+	n := len(node.vals)
+	node.vals = nil
+	if descendants {
+		n = node.count
+		node.children = [4]*qtNode{}
+	}
+	node.fold(idx.agg)
+	adjustCounts(path, -n)
+	idx.summarize(path)
+	return n
+}
+
+// Replace sets the values of the tile, removing any it had. Replacing with no values deletes the tile.
+func (idx *QuadtreeIndex) Replace(t Tile, val ...interface{}) {
+	idx.Lock()
+	defer idx.Unlock()
+	path := idx.path(t, len(val) > 0)
+	if path == nil {
+		return
+	}
+	n := path[len(path)-1]
+	delta := len(val) - len(n.vals)
+	// copy so later Adds can't append into the caller's slice
+	n.vals = append([]interface{}(nil), val...)
+	n.fold(idx.agg)
+	adjustCounts(path, delta)
+	idx.summarize(path)
+}
+
+// path returns the nodes from the root to the tile or nil if the tile isn't in the tree.
+// If create is set, missing nodes are added with a zero count.
+func (idx *QuadtreeIndex) path(t Tile, create bool) []*qtNode {
+	qk := t.Quadint()
+	path := make([]*qtNode, 1, t.Z+1)
+	path[0] = &idx.root
+	n := &idx.root
+	for i := 0; i < t.Z; i++ {
+		d := qk.digit(i)
+		if n.children[d] == nil {
+			if !create {
+				return nil
+			}
+			n.children[d] = idx.node()
+		}
+		n = n.children[d]
+		path = append(path, n)
+	}
+	return path
+}
+
+// node returns an empty node with identity summaries if the index aggregates
+func (idx *QuadtreeIndex) node() *qtNode {
+	n := &qtNode{}
+	n.fold(idx.agg)
+	return n
+}
+
+// summarize recomputes the summaries along the path from the deepest node up if the index aggregates
+func (idx *QuadtreeIndex) summarize(path []*qtNode) {
+	if idx.agg == nil {
+		return
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		path[i].refresh(idx.agg)
+	}
+}
+
+// adjustCounts adds delta to the counts along the path and prunes the nodes left empty
+func adjustCounts(path []*qtNode, delta int) {
+	for _, n := range path {
+		n.count += delta
+	}
+	for i := len(path) - 1; i > 0; i-- {
+		if path[i].count > 0 {
+			break
+		}
+		parent := path[i-1]
+		for d, c := range parent.children {
+			if c == path[i] {
+				parent.children[d] = nil
+			}
+		}
+	}
+}
+
+// appendValues appends the values of the node and its descendants in quadkey order
+func (n *qtNode) appendValues(vals []interface{}) []interface{} {
+	vals = append(vals, n.vals...)
+	for _, c := range n.children {
+		if c != nil {
+			vals = c.appendValues(vals)
+		}
+	}
+	return vals
+}
+
+// remove removes the values in the subtree that match and returns how many were removed.
+// Counts & summaries are updated and empty nodes pruned below the node, the caller updates the count & summary of the node itself.
+func (n *qtNode) remove(match func(interface{}) bool, agg Aggregator) int {
+	var removed int
+	n.vals, removed = removeValues(n.vals, match)
+	n.fold(agg)
+	for d, c := range n.children {
+		if c == nil {
+			continue
+		}
+		r := c.remove(match, agg)
+		c.count -= r
+		removed += r
+		if c.count == 0 {
+			n.children[d] = nil
+		} else if agg != nil {
+			c.refresh(agg)
+		}
+	}
+	return removed
+}
+
+// fold recomputes the summary of the node's own values, nothing happens if agg is nil
+func (n *qtNode) fold(agg Aggregator) {
+	if agg == nil {
+		return
+	}
+	s := agg.Identity()
+	for _, v := range n.vals {
+		s = agg.Combine(s, agg.Lift(v))
+	}
+	n.own = s
+}
+
+// refresh recomputes the summary of the node from its own summary and the summaries of its children
+func (n *qtNode) refresh(agg Aggregator) {
+	s := n.own
+	for _, c := range n.children {
+		if c != nil {
+			s = agg.Combine(s, c.summary)
+		}
+	}
+	n.summary = s
+}
@@ -0,0 +1,240 @@
+package tiles
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+)
+
+const (
+	snapshotMagic   = "TILESNAP"
+	snapshotVersion = uint64(1)
+)
+
+// The snapshot format written by WriteTo is:
+//
+//	magic "TILESNAP", uvarint version, uvarint tile count, then for each tile in quadkey order:
+//	big endian uint64 Quadint, uvarint value count, then for each value: uvarint length & the codec encoded value
+//
+// KeysetIndex and SuffixIndex write the same format, so a snapshot of one can be read by the other.
+
+// WriteTo writes a snapshot of the index to w with the index Codec.
+// It implements io.WriterTo and returns the number of bytes written.
+func (idx *KeysetIndex) WriteTo(w io.Writer) (int64, error) {
+	idx.Lock()
+	idx.sortKeys()
+	// Add appends a key per call, so merge the keys of a tile into one record
+	var keys []qkey
+	for _, k := range idx.keys {
+		if n := len(keys); n > 0 && keys[n-1].qk == k.qk {
+			keys[n-1].vals = append(keys[n-1].vals, k.vals...)
+			continue
+		}
+		keys = append(keys, qkey{qk: k.qk, vals: append([]interface{}(nil), k.vals...)})
+	}
+	codec := idx.Codec
+	// the keys are copied, so a slow writer doesn't block the index
+	idx.Unlock()
+	return writeSnapshot(w, codec, len(keys), func(i int) (Quadint, []interface{}) {
+		return keys[i].qk, keys[i].vals
+	})
+}
+
+// ReadFrom replaces the contents of the index with a snapshot read from r with the index Codec.
+// If the snapshot is invalid the index is left unchanged.
+// It implements io.ReaderFrom and returns the number of bytes read. Reading stops at the end of the snapshot,
+// so r isn't buffered unless it's an io.ByteReader, wrap it in a bufio.Reader to read faster.
+func (idx *KeysetIndex) ReadFrom(r io.Reader) (int64, error) {
+	var keys []qkey
+	n, err := readSnapshot(r, idx.Codec, func(qk Quadint, vals []interface{}) {
+		keys = append(keys, qkey{qk: qk, vals: vals})
+	})
+	if err != nil {
+		return n, err
+	}
+	idx.Lock()
+	defer idx.Unlock()
+	idx.keys = keys
+	idx.sorted = false
+	return n, nil
+}
+
+// WriteTo writes a snapshot of the index to w with the index Codec.
+// It implements io.WriterTo and returns the number of bytes written.
+func (idx *SuffixIndex) WriteTo(w io.Writer) (int64, error) {
+	keys := make([]Quadint, 0, len(idx.tiles))
+	for k := range idx.tiles {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return writeSnapshot(w, idx.Codec, len(keys), func(i int) (Quadint, []interface{}) {
+		return keys[i], idx.tiles[keys[i]]
+	})
+}
+
+// ReadFrom replaces the contents of the index with a snapshot read from r with the index Codec.
+// If the snapshot is invalid the index is left unchanged.
+// It implements io.ReaderFrom and returns the number of bytes read. Reading stops at the end of the snapshot,
+// so r isn't buffered unless it's an io.ByteReader, wrap it in a bufio.Reader to read faster.
+func (idx *SuffixIndex) ReadFrom(r io.Reader) (int64, error) {
+	tiles := make(map[Quadint][]interface{})
+	n, err := readSnapshot(r, idx.Codec, func(qk Quadint, vals []interface{}) {
+		tiles[qk] = append(tiles[qk], vals...)
+	})
+	if err != nil {
+		return n, err
+	}
+	idx.tiles = tiles
+	idx.index = nil
+	return n, nil
+}
+
+// writeSnapshot writes the n tiles returned by tile in the snapshot format, codec defaults to GobCodec
+func writeSnapshot(w io.Writer, codec Codec, n int, tile func(i int) (Quadint, []interface{})) (int64, error) {
+	if codec == nil {
+		codec = GobCodec{}
+	}
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	bw.WriteString(snapshotMagic)
+	writeUvarint(bw, snapshotVersion)
+	writeUvarint(bw, uint64(n))
+	for i := 0; i < n; i++ {
+		qk, vals := tile(i)
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(qk))
+		bw.Write(b[:])
+		writeUvarint(bw, uint64(len(vals)))
+		for _, v := range vals {
+			data, err := codec.Encode(v)
+			if err != nil {
+				bw.Flush()
+				return cw.n, err
+			}
+			writeUvarint(bw, uint64(len(data)))
+			bw.Write(data)
+		}
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// readSnapshot reads a snapshot and calls add with the values of each tile, codec defaults to GobCodec
+func readSnapshot(r io.Reader, codec Codec, add func(qk Quadint, vals []interface{})) (int64, error) {
+	if codec == nil {
+		codec = GobCodec{}
+	}
+	// read through the counter without buffering so nothing past the snapshot is consumed
+	br := &countReader{r: r}
+	invalid := func(err error) (int64, error) {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return br.n, errors.New("Invalid snapshot: " + err.Error())
+	}
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return invalid(err)
+	}
+	if string(magic) != snapshotMagic {
+		return invalid(errors.New("not a tile index snapshot"))
+	}
+	version, err := binary.ReadUvarint(br)
+	if err != nil {
+		return invalid(err)
+	}
+	if version != snapshotVersion {
+		return invalid(errors.New("unsupported version"))
+	}
+	tiles, err := binary.ReadUvarint(br)
+	if err != nil {
+		return invalid(err)
+	}
+	for i := uint64(0); i < tiles; i++ {
+		var b [8]byte
+		if _, err := io.ReadFull(br, b[:]); err != nil {
+			return invalid(err)
+		}
+		qk := Quadint(binary.BigEndian.Uint64(b[:]))
+		if qk.Level() > ZMax {
+			return invalid(errors.New("quadint level is greater than ZMax"))
+		}
+		n, err := binary.ReadUvarint(br)
+		if err != nil {
+			return invalid(err)
+		}
+		var vals []interface{}
+		for j := uint64(0); j < n; j++ {
+			size, err := binary.ReadUvarint(br)
+			if err != nil {
+				return invalid(err)
+			}
+			data, err := readN(br, size)
+			if err != nil {
+				return invalid(err)
+			}
+			v, err := codec.Decode(data)
+			if err != nil {
+				return invalid(err)
+			}
+			vals = append(vals, v)
+		}
+		add(qk, vals)
+	}
+	return br.n, nil
+}
+
+// readN reads exactly n bytes from r without allocating more than r has, in case n is corrupt
+func readN(r io.Reader, n uint64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(n)))
+	if err == nil && uint64(len(data)) != n {
+		err = io.ErrUnexpectedEOF
+	}
+	return data, err
+}
+
+func writeUvarint(w io.Writer, v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+// countWriter counts the bytes written to w
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// countReader counts the bytes read from r and reads single bytes from it unbuffered
+type countReader struct {
+	r io.Reader
+	n int64
+	b [1]byte
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countReader) ReadByte() (byte, error) {
+	if br, ok := c.r.(io.ByteReader); ok {
+		b, err := br.ReadByte()
+		if err == nil {
+			c.n++
+		}
+		return b, err
+	}
+	if _, err := io.ReadFull(c, c.b[:]); err != nil {
+		return 0, err
+	}
+	return c.b[0], nil
+}
@@ -0,0 +1,114 @@
+package tiles
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type snapshotIndex interface {
+	TileIndex
+	io.WriterTo
+	io.ReaderFrom
+}
+
+func TestSnapshot(t *testing.T) {
+	esb := FromCoordinate(40.7484, -73.9857, 18)
+	sol := FromCoordinate(40.6892, -74.0445, 18)
+	bbn := FromCoordinate(51.5007, -0.1246, 18)
+	nyc := Tile{X: 75, Y: 96, Z: 8}
+	root := Tile{}
+	snapshotTests := []struct {
+		src, dst snapshotIndex
+	}{
+		{&KeysetIndex{}, &KeysetIndex{}},
+		{NewSuffixIndex(), NewSuffixIndex()},
+		{&KeysetIndex{Codec: JSONCodec{}}, &SuffixIndex{tiles: make(map[Quadint][]interface{}), Codec: JSONCodec{}}},
+		{&SuffixIndex{tiles: make(map[Quadint][]interface{}), Codec: JSONCodec{}}, &KeysetIndex{Codec: JSONCodec{}}},
+	}
+	errf := "%T -> %T snapshot: %s %v"
+	for _, test := range snapshotTests {
+		test.src.Add(esb, "EmpireStateBuilding")
+		test.src.Add(sol, "StatueOfLiberty", "Ferry")
+		test.src.Add(bbn, "BigBen")
+		test.src.Add(esb, "Bus")
+		test.dst.Add(Tile{X: 1, Y: 1, Z: 1}, "Replaced")
+		var buf bytes.Buffer
+		if n, err := test.src.WriteTo(&buf); err != nil || n != int64(buf.Len()) {
+			t.Errorf(errf, test.src, test.dst, "WriteTo", err)
+			continue
+		}
+		snapshot := buf.Bytes()
+		// the tile count follows the magic & version, each tile is written once
+		if tiles := snapshot[len(snapshotMagic)+1]; tiles != 3 {
+			t.Errorf(errf, test.src, test.dst, "WriteTo tile count", tiles)
+		}
+		if n, err := test.dst.ReadFrom(bytes.NewReader(snapshot)); err != nil || n != int64(len(snapshot)) {
+			t.Errorf(errf, test.src, test.dst, "ReadFrom", err)
+			continue
+		}
+		for _, tile := range []Tile{esb, sol, bbn, nyc, root} {
+			// SuffixIndex doesn't order values across tiles
+			if got, want := countValues(test.dst.Values(tile)), countValues(test.src.Values(tile)); !reflect.DeepEqual(got, want) {
+				t.Errorf(errf, test.src, test.dst, "Values", got)
+			}
+		}
+		// truncated or corrupt snapshots leave the index unchanged
+		for _, bad := range [][]byte{snapshot[:len(snapshot)-1], snapshot[:5], []byte("TILESNAX\x01\x00"), {}} {
+			if _, err := test.dst.ReadFrom(bytes.NewReader(bad)); err == nil {
+				t.Errorf(errf, test.src, test.dst, "ReadFrom invalid", bad)
+			}
+			if vals := test.dst.Values(root); len(vals) != 5 {
+				t.Errorf(errf, test.src, test.dst, "Values after invalid ReadFrom", vals)
+			}
+		}
+		// back to back snapshots are read one at a time whether or not r is buffered
+		twice := append(append([]byte(nil), snapshot...), snapshot...)
+		for _, r := range []io.Reader{struct{ io.Reader }{bytes.NewReader(twice)}, bufio.NewReader(bytes.NewReader(twice))} {
+			for i := 0; i < 2; i++ {
+				if n, err := test.dst.ReadFrom(r); err != nil || n != int64(len(snapshot)) {
+					t.Errorf(errf, test.src, test.dst, "ReadFrom back to back", err)
+				}
+			}
+		}
+	}
+}
+
+func countValues(vals []interface{}) map[interface{}]int {
+	counts := make(map[interface{}]int)
+	for _, v := range vals {
+		counts[v]++
+	}
+	return counts
+}
+
+func TestSnapshotUnlocked(t *testing.T) {
+	idx := &KeysetIndex{}
+	idx.Add(Tile{}, "a")
+	// the snapshot is written after the index is unlocked, so it can be written to while w blocks
+	r, w := io.Pipe()
+	done := make(chan error)
+	go func() {
+		_, err := idx.WriteTo(w)
+		w.Close()
+		done <- err
+	}()
+	// an io.Pipe write blocks until all of it is read, so WriteTo is still writing after one byte is
+	first := make([]byte, 1)
+	if _, err := io.ReadFull(r, first); err != nil {
+		t.Fatal("KeysetIndex.WriteTo: ", err)
+	}
+	idx.Add(Tile{}, "b")
+	if vals := idx.Values(Tile{}); len(vals) != 2 {
+		t.Errorf("KeysetIndex.Values while writing a snapshot -> %v", vals)
+	}
+	dst := &KeysetIndex{}
+	if _, err := dst.ReadFrom(io.MultiReader(bytes.NewReader(first), r)); err != nil {
+		t.Error("KeysetIndex.ReadFrom: ", err)
+	}
+	if err := <-done; err != nil {
+		t.Error("KeysetIndex.WriteTo: ", err)
+	}
+}
@@ -1,22 +1,43 @@
 // Package tiles is a collection of conversion utilities to go between geo/pixel/tile/quadkey space
-// This package uses WGS84 coordinates and a mercator projection
+// This package uses WGS84 coordinates and a mercator projection, other tile pyramids can be described with a TileMatrixSet
 // There is also a TileIndex which can be used to store data in a single place and aggregate when needed
 package tiles
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Tile is a simple struct for holding the XYZ coordinates for use in mapping
 type Tile struct {
 	X, Y, Z int
 }
 
+// NewTile returns a Tile after validating its coordinates.
+// Use this constructor for tile coordinates that come from untrusted input.
+func NewTile(x, y, z int) (Tile, error) {
+	t := Tile{X: x, Y: y, Z: z}
+	if err := t.Validate(); err != nil {
+		return Tile{}, err
+	}
+	return t, nil
+}
+
+// Validate returns an error if the zoom is outside of [0, ZMax] or X/Y are outside of [0, 2^Z)
+func (t Tile) Validate() error {
+	if err := ValidateZoom(t.Z); err != nil {
+		return err
+	}
+	n := 1 << uint(t.Z)
+	if t.X < 0 || t.X >= n || t.Y < 0 || t.Y >= n {
+		return fmt.Errorf("Invalid Tile %+v: X and Y must be in [0, %d)", t, n)
+	}
+	return nil
+}
+
 // ToPixel return the NW pixel of this tile
 func (t Tile) ToPixel() Pixel {
-	return Pixel{
-		X: t.X * TileSize,
-		Y: t.Y * TileSize,
-		Z: t.Z,
-	}
+	return defaultGrid().TileToPixel(t)
 }
 
 // ToPixelWithOffset returns a pixel at the origin with an offset added. Useful for getting the center pixel of a tile or another non-origin pixel.
@@ -27,8 +48,96 @@ func (t Tile) ToPixelWithOffset(offset Pixel) (pixel Pixel) {
 	return
 }
 
+// Bounds returns the WGS84 extent of this tile.
+// The edges are exact, neighboring tiles share the same edge values.
+func (t Tile) Bounds() BBox {
+	nw := tileCoords(float64(t.X), float64(t.Y), t.Z)
+	se := tileCoords(float64(t.X+1), float64(t.Y+1), t.Z)
+	return BBox{
+		North: nw.Lat,
+		South: se.Lat,
+		East:  se.Lon,
+		West:  nw.Lon,
+	}
+}
+
+// Center returns the coordinate at the center of this tile in the mercator projection
+func (t Tile) Center() Coordinate {
+	return tileCoords(float64(t.X)+0.5, float64(t.Y)+0.5, t.Z)
+}
+
+// Neighbors returns the 8-connected tiles around this tile.
+// X wraps across the antimeridian and rows past the poles are left out.
+func (t Tile) Neighbors() []Tile {
+	return t.Ring(1)
+}
+
+// Siblings returns the other tiles that share the same parent as this tile.
+// The root tile has no siblings.
+func (t Tile) Siblings() []Tile {
+	if t.Z == 0 {
+		return nil
+	}
+	siblings := make([]Tile, 0, 3)
+	for y := t.Y &^ 1; y <= t.Y|1; y++ {
+		for x := t.X &^ 1; x <= t.X|1; x++ {
+			if x != t.X || y != t.Y {
+				siblings = append(siblings, Tile{X: x, Y: y, Z: t.Z})
+			}
+		}
+	}
+	return siblings
+}
+
+// Ring returns the tiles that are exactly k tiles away from this tile.
+// X wraps across the antimeridian and rows past the poles are left out, so rings at low zoom levels may be partial.
+func (t Tile) Ring(k int) []Tile {
+	return t.within(k, func(d int) bool { return d == k })
+}
+
+// Disk returns the tiles that are at most k tiles away from this tile, including itself.
+// X wraps across the antimeridian and rows past the poles are left out.
+func (t Tile) Disk(k int) []Tile {
+	return t.within(k, func(d int) bool { return d <= k })
+}
+
+// within returns the unique tiles in the square of radius k whose wrapped distance from t is accepted by fn.
+// Tiles are ordered by row then column starting from the NW.
+func (t Tile) within(k int, fn func(d int) bool) (tiles []Tile) {
+	if k < 0 {
+		return nil
+	}
+	n := 1 << uint(t.Z)
+	seen := make(map[Tile]struct{})
+	for dy := -k; dy <= k; dy++ {
+		y := t.Y + dy
+		if y < 0 || y >= n {
+			continue
+		}
+		for dx := -k; dx <= k; dx++ {
+			x := ((t.X+dx)%n + n) % n
+			// shortest distance around the antimeridian
+			ax := abs(x - t.X)
+			if n-ax < ax {
+				ax = n - ax
+			}
+			d := ax
+			if abs(dy) > d {
+				d = abs(dy)
+			}
+			tile := Tile{X: x, Y: y, Z: t.Z}
+			if _, ok := seen[tile]; ok || !fn(d) {
+				continue
+			}
+			seen[tile] = struct{}{}
+			tiles = append(tiles, tile)
+		}
+	}
+	return
+}
+
 // Quadkey returns the string representation of a Bing Maps quadkey. See more https://msdn.microsoft.com/en-us/library/bb259689.aspx
-// Panics if the tile is invalid or if it can't write to the internal buffer
+// Panics if the zoom is invalid and returns a wrong key for out of range X/Y, use QuadkeyChecked for untrusted tiles
 func (t Tile) Quadkey() Quadkey {
 	//bytes.Buffer was bottleneck
 	z := t.Z
@@ -61,8 +170,20 @@ func (t Tile) Quadkey() Quadkey {
 	return Quadkey(qk[:z]) // current bottleneck
 }
 
+// QuadkeyChecked returns the Quadkey of the tile or an error if the tile is invalid
+func (t Tile) QuadkeyChecked() (Quadkey, error) {
+	if err := t.Validate(); err != nil {
+		return "", err
+	}
+	return t.Quadkey(), nil
+}
+
 // FromQuadkeyString returns a tile that represents the given quadkey string. Returns an error if quadkey string is invalid.
 func FromQuadkeyString(qk string) (tile Tile, err error) {
+	if len(qk) > ZMax {
+		err = errors.New("Invalid Quadkey " + qk)
+		return
+	}
 	tile.Z = len(qk)
 	for i := tile.Z; i > 0; i-- {
 		m := 1 << uint(i-1)
@@ -88,9 +209,7 @@ func FromQuadkeyString(qk string) (tile Tile, err error) {
 
 // FromCoordinate take float lat/lons and a zoom and return a tile
 // Clips the coordinates if they are outside of Min/MaxLat/Lon
+// Use WrappedCoords(lat, lon).ToTile(zoom) for longitudes that should wrap around the antimeridian instead
 func FromCoordinate(lat, lon float64, zoom int) Tile {
-	c := ClippedCoords(lat, lon)
-	p := c.ToPixel(zoom)
-	t, _ := p.ToTile()
-	return t
+	return defaultGrid().FromCoordinate(lat, lon, zoom)
 }
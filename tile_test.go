@@ -7,6 +7,36 @@ import (
 	"github.com/buckhx/tiles"
 )
 
+func TestNewTile(t *testing.T) {
+	tileTests := []struct {
+		x, y, z int
+		err     bool
+	}{
+		{0, 0, 0, false},
+		{26, 48, 7, false},
+		{127, 127, 7, false},
+		{128, 0, 7, true},
+		{0, 128, 7, true},
+		{-1, 0, 7, true},
+		{0, 0, -1, true},
+		{0, 0, tiles.ZMax + 1, true},
+	}
+	errf := "NewTile(%d, %d, %d) -> %+v, %v"
+	for _, test := range tileTests {
+		tile, err := tiles.NewTile(test.x, test.y, test.z)
+		if (err != nil) != test.err {
+			t.Errorf(errf, test.x, test.y, test.z, tile, err)
+		}
+		if _, err := tile.QuadkeyChecked(); err != nil && !test.err {
+			t.Errorf("Tile%+v.QuadkeyChecked() -> %v", tile, err)
+		}
+	}
+	bad := tiles.Tile{X: 128, Y: 0, Z: 7}
+	if qk, err := bad.QuadkeyChecked(); err == nil {
+		t.Errorf("Tile%+v.QuadkeyChecked() -> %q should be an error", bad, qk)
+	}
+}
+
 func TestTileToPixel(t *testing.T) {
 	tileTests := []struct {
 		tile  tiles.Tile
@@ -56,10 +86,139 @@ func TestTileFromQuadkey(t *testing.T) {
 	}
 }
 
+func TestTileBounds(t *testing.T) {
+	tileTests := []struct {
+		tile   tiles.Tile
+		bounds tiles.BBox
+	}{
+		{tiles.Tile{X: 0, Y: 0, Z: 0}, tiles.BBox{North: tiles.MaxLat, South: tiles.MinLat, East: 180, West: -180}},
+		{tiles.Tile{X: 1, Y: 1, Z: 1}, tiles.BBox{North: 0, South: tiles.MinLat, East: 180, West: 0}},
+		{tiles.Tile{X: 26, Y: 48, Z: 7}, tiles.BBox{North: 40.979898069620134, South: 38.8225909761771, East: -104.0625, West: -106.875}},
+	}
+	errf := "Tile%+v: %+v -> %+v"
+	for _, test := range tileTests {
+		bounds := test.tile.Bounds()
+		if !bounds.Equals(test.bounds) {
+			t.Errorf(errf, test.tile, test.bounds, bounds)
+		}
+	}
+}
+
+func TestTileBoundsShareEdges(t *testing.T) {
+	tile := tiles.Tile{X: 26, Y: 48, Z: 7}
+	east := tiles.Tile{X: 27, Y: 48, Z: 7}.Bounds()
+	south := tiles.Tile{X: 26, Y: 49, Z: 7}.Bounds()
+	b := tile.Bounds()
+	if b.East != east.West || b.South != south.North {
+		t.Errorf("Tile%+v bounds %+v do not share edges with %+v %+v", tile, b, east, south)
+	}
+}
+
+func TestTileCenter(t *testing.T) {
+	tileTests := []struct {
+		tile   tiles.Tile
+		center tiles.Coordinate
+	}{
+		{tiles.Tile{X: 0, Y: 0, Z: 0}, tiles.Coordinate{Lat: 0, Lon: 0}},
+		{tiles.Tile{X: 0, Y: 0, Z: 1}, tiles.Coordinate{Lat: 66.51326044311186, Lon: -90}},
+	}
+	errf := "Tile%+v: %+v -> %+v"
+	for _, test := range tileTests {
+		center := test.tile.Center()
+		if !center.Equals(test.center) {
+			t.Errorf(errf, test.tile, test.center, center)
+		}
+	}
+}
+
+func TestTileNeighbors(t *testing.T) {
+	tileTests := []struct {
+		tile      tiles.Tile
+		neighbors []tiles.Tile
+	}{
+		{tiles.Tile{X: 0, Y: 0, Z: 0}, nil},
+		{tiles.Tile{X: 0, Y: 0, Z: 1}, []tiles.Tile{{X: 1, Y: 0, Z: 1}, {X: 1, Y: 1, Z: 1}, {X: 0, Y: 1, Z: 1}}},
+		{tiles.Tile{X: 5, Y: 5, Z: 4}, []tiles.Tile{
+			{X: 4, Y: 4, Z: 4}, {X: 5, Y: 4, Z: 4}, {X: 6, Y: 4, Z: 4},
+			{X: 4, Y: 5, Z: 4}, {X: 6, Y: 5, Z: 4},
+			{X: 4, Y: 6, Z: 4}, {X: 5, Y: 6, Z: 4}, {X: 6, Y: 6, Z: 4},
+		}},
+		{tiles.Tile{X: 0, Y: 0, Z: 3}, []tiles.Tile{
+			{X: 7, Y: 0, Z: 3}, {X: 1, Y: 0, Z: 3},
+			{X: 7, Y: 1, Z: 3}, {X: 0, Y: 1, Z: 3}, {X: 1, Y: 1, Z: 3},
+		}},
+	}
+	errf := "Tile%+v.Neighbors() -> %+v"
+	for _, test := range tileTests {
+		neighbors := test.tile.Neighbors()
+		if !tileSetEqual(neighbors, test.neighbors) {
+			t.Errorf(errf, test.tile, neighbors)
+		}
+	}
+}
+
+func TestTileSiblings(t *testing.T) {
+	tileTests := []struct {
+		tile     tiles.Tile
+		siblings []tiles.Tile
+	}{
+		{tiles.Tile{X: 0, Y: 0, Z: 0}, nil},
+		{tiles.Tile{X: 3, Y: 2, Z: 3}, []tiles.Tile{{X: 2, Y: 2, Z: 3}, {X: 2, Y: 3, Z: 3}, {X: 3, Y: 3, Z: 3}}},
+	}
+	errf := "Tile%+v.Siblings() -> %+v"
+	for _, test := range tileTests {
+		siblings := test.tile.Siblings()
+		if !tileSetEqual(siblings, test.siblings) {
+			t.Errorf(errf, test.tile, siblings)
+		}
+	}
+}
+
+func TestTileRingDisk(t *testing.T) {
+	tileTests := []struct {
+		tile       tiles.Tile
+		k          int
+		ring, disk int
+	}{
+		{tiles.Tile{X: 100, Y: 100, Z: 10}, 0, 1, 1},
+		{tiles.Tile{X: 100, Y: 100, Z: 10}, 2, 16, 25},
+		{tiles.Tile{X: 100, Y: 0, Z: 10}, 2, 9, 15},
+		{tiles.Tile{X: 0, Y: 100, Z: 10}, 3, 24, 49},
+		{tiles.Tile{X: 0, Y: 1, Z: 2}, 2, 7, 16},
+		{tiles.Tile{X: 0, Y: 1, Z: 2}, 3, 0, 16},
+	}
+	errf := "Tile%+v.%s(%d) -> %d tiles"
+	for _, test := range tileTests {
+		if ring := test.tile.Ring(test.k); len(ring) != test.ring {
+			t.Errorf(errf, test.tile, "Ring", test.k, len(ring))
+		}
+		if disk := test.tile.Disk(test.k); len(disk) != test.disk {
+			t.Errorf(errf, test.tile, "Disk", test.k, len(disk))
+		}
+	}
+}
+
+func tileSetEqual(x, y []tiles.Tile) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	set := make(map[tiles.Tile]struct{}, len(x))
+	for _, t := range x {
+		set[t] = struct{}{}
+	}
+	for _, t := range y {
+		if _, ok := set[t]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 var (
 	// These are globals to make sure that the compiler doesn't skip benchmarks
 	bT tiles.Tile
 	bQ tiles.Quadkey
+	bI tiles.Quadint
 )
 
 func BenchmarkTileFromCoordinate(b *testing.B) {
@@ -92,6 +251,15 @@ func BenchmarkQuadkeyFromTile(b *testing.B) {
 	bQ = q
 }
 
+func BenchmarkQuadintFromTile(b *testing.B) {
+	var q tiles.Quadint
+	t := tiles.Tile{X: 77197, Y: 98526, Z: 18}
+	for i := 0; i < b.N; i++ {
+		q = t.Quadint()
+	}
+	bI = q
+}
+
 func ExampleFromCoordinate() {
 	esbLat := 40.7484
 	esbLon := -73.9857
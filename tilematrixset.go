@@ -0,0 +1,223 @@
+package tiles
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Projection converts between WGS84 coordinates and the x, y units of a CRS
+type Projection interface {
+	Project(c Coordinate) (x, y float64)
+	Unproject(x, y float64) Coordinate
+}
+
+// MercatorProjection projects WGS84 coordinates to Web Mercator (EPSG:3857) meters
+type MercatorProjection struct{}
+
+// Project converts the coord to Web Mercator meters
+func (MercatorProjection) Project(c Coordinate) (x, y float64) {
+	m := c.ToMercator()
+	return m.X, m.Y
+}
+
+// Unproject converts Web Mercator meters to WGS84 coordinates
+func (MercatorProjection) Unproject(x, y float64) Coordinate {
+	return Mercator{X: x, Y: y}.ToCoords()
+}
+
+// LonLatProjection uses WGS84 degrees directly with x as longitude and y as latitude (EPSG:4326/CRS84)
+type LonLatProjection struct{}
+
+// Project returns the lon, lat of the coord
+func (LonLatProjection) Project(c Coordinate) (x, y float64) {
+	return c.Lon, c.Lat
+}
+
+// Unproject returns the coord at lon, lat
+func (LonLatProjection) Unproject(x, y float64) Coordinate {
+	return Coordinate{Lat: y, Lon: x}
+}
+
+// TileMatrix is the grid of tiles at a single zoom level of a TileMatrixSet
+type TileMatrix struct {
+	// Resolution is the size of a pixel in CRS units
+	Resolution float64
+	// MatrixWidth and MatrixHeight are the number of tiles along each axis
+	MatrixWidth, MatrixHeight int
+}
+
+// TileMatrixSet describes a tile pyramid in an arbitrary CRS like the OGC TileMatrixSet standard.
+// Tiles are indexed from the top left Origin with X increasing to the right and Y increasing down.
+// The zoom of a Tile is the index of its TileMatrix in Matrices.
+type TileMatrixSet struct {
+	ID         string
+	CRS        string
+	Projection Projection
+	// OriginX, OriginY is the top left corner of every matrix in CRS units
+	OriginX, OriginY float64
+	// Extent is the bounds of the set in CRS units
+	Extent   Extent
+	TileSize int
+	Matrices []TileMatrix
+}
+
+// WebMercatorQuad returns the Web Mercator (EPSG:3857) tile matrix set used by most web maps.
+// It has a single tile at zoom 0 and its tiles have the same Bounds as the Tiles of this package.
+func WebMercatorQuad() *TileMatrixSet {
+	tms, err := NewQuadTileMatrixSet("WebMercatorQuad", "EPSG:3857", MercatorProjection{},
+		Extent{MinX: -MercatorExtent, MinY: -MercatorExtent, MaxX: MercatorExtent, MaxY: MercatorExtent},
+		256, 1, 1, ZMax)
+	check(err)
+	return tms
+}
+
+// WorldCRS84Quad returns the plate carrée (EPSG:4326) tile matrix set with 2x1 tiles at zoom 0
+func WorldCRS84Quad() *TileMatrixSet {
+	tms, err := NewQuadTileMatrixSet("WorldCRS84Quad", "EPSG:4326", LonLatProjection{},
+		Extent{MinX: -180, MinY: -90, MaxX: 180, MaxY: 90},
+		256, 2, 1, ZMax)
+	check(err)
+	return tms
+}
+
+// NewQuadTileMatrixSet returns a tile matrix set over the extent with width x height tiles at zoom 0
+// that doubles along each axis at every zoom up to maxZoom. The origin is the top left of the extent.
+// Returns an error if the set is invalid.
+func NewQuadTileMatrixSet(id, crs string, proj Projection, extent Extent, tileSize, width, height, maxZoom int) (*TileMatrixSet, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("Invalid matrix size %dx%d: must be positive", width, height)
+	}
+	if maxZoom < 0 || maxZoom > ZMax {
+		return nil, ValidateZoom(maxZoom)
+	}
+	tms := &TileMatrixSet{
+		ID:         id,
+		CRS:        crs,
+		Projection: proj,
+		OriginX:    extent.MinX,
+		OriginY:    extent.MaxY,
+		Extent:     extent,
+		TileSize:   tileSize,
+		Matrices:   make([]TileMatrix, maxZoom+1),
+	}
+	res := (extent.MaxX - extent.MinX) / float64(width*tileSize)
+	for z := range tms.Matrices {
+		tms.Matrices[z] = TileMatrix{
+			Resolution:   res / float64(uint64(1)<<uint(z)),
+			MatrixWidth:  width << uint(z),
+			MatrixHeight: height << uint(z),
+		}
+	}
+	if err := tms.Validate(); err != nil {
+		return nil, err
+	}
+	return tms, nil
+}
+
+// Validate returns an error if the set can't be used for conversions
+func (tms *TileMatrixSet) Validate() error {
+	switch {
+	case tms.Projection == nil:
+		return errors.New("Invalid TileMatrixSet " + tms.ID + ": missing Projection")
+	case tms.TileSize <= 0:
+		return fmt.Errorf("Invalid TileMatrixSet %s: TileSize %d must be positive", tms.ID, tms.TileSize)
+	case len(tms.Matrices) == 0:
+		return errors.New("Invalid TileMatrixSet " + tms.ID + ": no Matrices")
+	}
+	for z, m := range tms.Matrices {
+		if m.Resolution <= 0 || m.MatrixWidth <= 0 || m.MatrixHeight <= 0 {
+			return fmt.Errorf("Invalid TileMatrixSet %s: matrix %d %+v", tms.ID, z, m)
+		}
+	}
+	return nil
+}
+
+// MaxZoom returns the deepest zoom level of the set
+func (tms *TileMatrixSet) MaxZoom() int {
+	return len(tms.Matrices) - 1
+}
+
+// Matrix returns the TileMatrix at the zoom or an error if the set doesn't have that zoom
+func (tms *TileMatrixSet) Matrix(zoom int) (TileMatrix, error) {
+	if zoom < 0 || zoom >= len(tms.Matrices) {
+		return TileMatrix{}, fmt.Errorf("Invalid zoom %d for TileMatrixSet %s: must be in [0, %d]", zoom, tms.ID, tms.MaxZoom())
+	}
+	return tms.Matrices[zoom], nil
+}
+
+// ValidateTile returns an error if the tile is not in the set
+func (tms *TileMatrixSet) ValidateTile(t Tile) error {
+	m, err := tms.Matrix(t.Z)
+	if err != nil {
+		return err
+	}
+	if t.X < 0 || t.X >= m.MatrixWidth || t.Y < 0 || t.Y >= m.MatrixHeight {
+		return fmt.Errorf("Invalid Tile %+v for TileMatrixSet %s: X must be in [0, %d) and Y in [0, %d)", t, tms.ID, m.MatrixWidth, m.MatrixHeight)
+	}
+	return nil
+}
+
+// FromCoordinate returns the tile that contains the coord at the zoom level.
+// Coordinates outside of the set are clipped to the edge tiles.
+func (tms *TileMatrixSet) FromCoordinate(c Coordinate, zoom int) (Tile, error) {
+	p, err := tms.CoordinateToPixel(c, zoom)
+	if err != nil {
+		return Tile{}, err
+	}
+	return Tile{X: p.X / tms.TileSize, Y: p.Y / tms.TileSize, Z: zoom}, nil
+}
+
+// CoordinateToPixel returns the pixel that contains the coord at the zoom level.
+// Coordinates outside of the set are clipped to the edge pixels.
+func (tms *TileMatrixSet) CoordinateToPixel(c Coordinate, zoom int) (Pixel, error) {
+	m, err := tms.Matrix(zoom)
+	if err != nil {
+		return Pixel{}, err
+	}
+	x, y := tms.Projection.Project(c)
+	w := float64(m.MatrixWidth * tms.TileSize)
+	h := float64(m.MatrixHeight * tms.TileSize)
+	return Pixel{
+		X: int(clip(math.Floor((x-tms.OriginX)/m.Resolution), 0, w-1)),
+		Y: int(clip(math.Floor((tms.OriginY-y)/m.Resolution), 0, h-1)),
+		Z: zoom,
+	}, nil
+}
+
+// PixelToCoords converts the NW corner of the pixel to WGS84 coordinates
+func (tms *TileMatrixSet) PixelToCoords(p Pixel) (Coordinate, error) {
+	m, err := tms.Matrix(p.Z)
+	if err != nil {
+		return Coordinate{}, err
+	}
+	return tms.Projection.Unproject(
+		tms.OriginX+p.floatX()*m.Resolution,
+		tms.OriginY-p.floatY()*m.Resolution,
+	), nil
+}
+
+// TileExtent returns the extent of the tile in CRS units
+func (tms *TileMatrixSet) TileExtent(t Tile) (Extent, error) {
+	if err := tms.ValidateTile(t); err != nil {
+		return Extent{}, err
+	}
+	span := tms.Matrices[t.Z].Resolution * float64(tms.TileSize)
+	return Extent{
+		MinX: tms.OriginX + float64(t.X)*span,
+		MinY: tms.OriginY - float64(t.Y+1)*span,
+		MaxX: tms.OriginX + float64(t.X+1)*span,
+		MaxY: tms.OriginY - float64(t.Y)*span,
+	}, nil
+}
+
+// TileBounds returns the WGS84 extent of the tile by unprojecting the corners of its CRS extent
+func (tms *TileMatrixSet) TileBounds(t Tile) (BBox, error) {
+	e, err := tms.TileExtent(t)
+	if err != nil {
+		return BBox{}, err
+	}
+	nw := tms.Projection.Unproject(e.MinX, e.MaxY)
+	se := tms.Projection.Unproject(e.MaxX, e.MinY)
+	return BBox{North: nw.Lat, South: se.Lat, East: se.Lon, West: nw.Lon}, nil
+}
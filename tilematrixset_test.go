@@ -0,0 +1,82 @@
+package tiles
+
+import (
+	"testing"
+)
+
+func TestWebMercatorQuad(t *testing.T) {
+	tms := WebMercatorQuad()
+	esb := Coordinate{40.7484, -73.9857}
+	for _, z := range []int{0, 7, 18} {
+		tile, err := tms.FromCoordinate(esb, z)
+		if err != nil || tile != FromCoordinate(esb.Lat, esb.Lon, z) {
+			t.Errorf("WebMercatorQuad.FromCoordinate(%v, %d) -> %+v, %v", esb, z, tile, err)
+		}
+		b, err := tms.TileBounds(tile)
+		if err != nil || !b.Equals(tile.Bounds()) {
+			t.Errorf("WebMercatorQuad.TileBounds(%+v) -> %+v, %v", tile, b, err)
+		}
+		e, err := tms.TileExtent(tile)
+		if err != nil || e != tile.BoundsMercator() {
+			t.Errorf("WebMercatorQuad.TileExtent(%+v) -> %+v, %v", tile, e, err)
+		}
+	}
+}
+
+func TestWorldCRS84Quad(t *testing.T) {
+	tms := WorldCRS84Quad()
+	tmsTests := []struct {
+		coords Coordinate
+		zoom   int
+		tile   Tile
+	}{
+		{Coordinate{40.7484, -73.9857}, 0, Tile{0, 0, 0}},
+		{Coordinate{-40.7484, 73.9857}, 0, Tile{1, 0, 0}},
+		{Coordinate{40.7484, -73.9857}, 1, Tile{1, 0, 1}},
+		{Coordinate{-89.9, 179.9}, 2, Tile{7, 3, 2}},
+		{Coordinate{90, -180}, 2, Tile{0, 0, 2}},
+	}
+	errf := "WorldCRS84Quad.FromCoordinate(%v, %d) -> %+v, %v"
+	for _, test := range tmsTests {
+		tile, err := tms.FromCoordinate(test.coords, test.zoom)
+		if err != nil || tile != test.tile {
+			t.Errorf(errf, test.coords, test.zoom, tile, err)
+		}
+	}
+	b, _ := tms.TileBounds(Tile{1, 0, 1})
+	if !b.Equals(BBox{North: 90, South: 0, East: 0, West: -90}) {
+		t.Errorf("WorldCRS84Quad.TileBounds() -> %+v", b)
+	}
+	if m, _ := tms.Matrix(0); m.MatrixWidth != 2 || m.MatrixHeight != 1 || m.Resolution != 0.703125 {
+		t.Errorf("WorldCRS84Quad.Matrix(0) -> %+v", m)
+	}
+	if err := tms.ValidateTile(Tile{4, 0, 1}); err == nil {
+		t.Error("WorldCRS84Quad.ValidateTile({4, 0, 1}) should be an error")
+	}
+	if _, err := tms.FromCoordinate(Coordinate{}, ZMax+1); err == nil {
+		t.Error("WorldCRS84Quad.FromCoordinate() past MaxZoom should be an error")
+	}
+}
+
+func TestCustomTileMatrixSet(t *testing.T) {
+	// A grid over a local extent with 8 units per pixel at zoom 0
+	extent := Extent{MinX: 0, MinY: 0, MaxX: 4096, MaxY: 2048}
+	tms, err := NewQuadTileMatrixSet("Local", "LOCAL", LonLatProjection{}, extent, 256, 2, 1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tms.MaxZoom() != 4 {
+		t.Errorf("Custom.MaxZoom() -> %d", tms.MaxZoom())
+	}
+	p, _ := tms.CoordinateToPixel(Coordinate{Lat: 2047, Lon: 1}, 1)
+	if p != (Pixel{0, 0, 1}) {
+		t.Errorf("Custom.CoordinateToPixel() -> %+v", p)
+	}
+	c, _ := tms.PixelToCoords(Pixel{256, 256, 1})
+	if !c.Equals(Coordinate{Lat: 1024, Lon: 1024}) {
+		t.Errorf("Custom.PixelToCoords() -> %+v", c)
+	}
+	if tms, err := NewQuadTileMatrixSet("Bad", "LOCAL", nil, extent, 256, 2, 1, 4); err == nil || tms != nil {
+		t.Error("NewQuadTileMatrixSet() without a Projection should be an error")
+	}
+}
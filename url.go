@@ -0,0 +1,136 @@
+package tiles
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TMS returns the tile with its Y flipped for the TMS scheme, which has a SW origin instead of NW.
+// Flipping a TMS tile with TMS returns the original tile.
+func (t Tile) TMS() Tile {
+	return Tile{X: t.X, Y: (1 << uint(t.Z)) - 1 - t.Y, Z: t.Z}
+}
+
+// FromTMS returns the tile at the TMS coordinates, which have a SW origin instead of NW.
+func FromTMS(x, y, z int) Tile {
+	return Tile{X: x, Y: y, Z: z}.TMS()
+}
+
+// URLTemplate expands and parses tile URLs such as "https://{s}.tile.example.com/{z}/{x}/{y}.png".
+// Supported placeholders are {z}, {x}, {y}, {-y} (the TMS y), {q} (the quadkey) and {s} (a subdomain).
+type URLTemplate struct {
+	Template string
+	// Subdomains are chosen for {s} by the tile X & Y so the same tile always uses the same subdomain
+	Subdomains []string
+}
+
+// Expand fills in the placeholders of the template with the tile.
+// Returns an error if the tile is invalid, since it has no quadkey or TMS y.
+func (u URLTemplate) Expand(t Tile) (string, error) {
+	if err := t.Validate(); err != nil {
+		return "", err
+	}
+	s := ""
+	if len(u.Subdomains) > 0 {
+		s = u.Subdomains[abs(t.X+t.Y)%len(u.Subdomains)]
+	}
+	r := strings.NewReplacer(
+		"{z}", strconv.Itoa(t.Z),
+		"{x}", strconv.Itoa(t.X),
+		"{y}", strconv.Itoa(t.Y),
+		"{-y}", strconv.Itoa(t.TMS().Y),
+		"{q}", string(t.Quadkey()),
+		"{s}", s,
+	)
+	return r.Replace(u.Template), nil
+}
+
+var placeholders = regexp.MustCompile(`\{(z|x|y|-y|q|s)\}`)
+
+// Parse returns the tile from a URL or path that ends with the template.
+// Returns an error if it doesn't match the template or if the tile is invalid.
+func (u URLTemplate) Parse(url string) (Tile, error) {
+	re, groups, err := u.regexp()
+	if err != nil {
+		return Tile{}, err
+	}
+	m := re.FindStringSubmatch(url)
+	if m == nil {
+		return Tile{}, fmt.Errorf("URL %q does not match template %q", url, u.Template)
+	}
+	vals := make(map[string]string)
+	for i, name := range groups {
+		if prev, ok := vals[name]; ok && prev != m[i+1] {
+			return Tile{}, fmt.Errorf("URL %q has conflicting values for {%s}", url, name)
+		}
+		vals[name] = m[i+1]
+	}
+	var t Tile
+	if q, ok := vals["q"]; ok {
+		if t, err = Quadkey(q).ToTileChecked(); err != nil {
+			return Tile{}, err
+		}
+	}
+	for _, name := range []string{"z", "x", "y", "-y"} {
+		v, ok := vals[name]
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return Tile{}, err
+		}
+		switch name {
+		case "z":
+			t.Z = n
+		case "x":
+			t.X = n
+		case "y":
+			t.Y = n
+		case "-y":
+			t.Y = FromTMS(0, n, t.Z).Y
+		}
+	}
+	if err := t.Validate(); err != nil {
+		return Tile{}, err
+	}
+	if q, ok := vals["q"]; ok && Quadkey(q) != t.Quadkey() {
+		return Tile{}, fmt.Errorf("URL %q has a quadkey that conflicts with its z/x/y", url)
+	}
+	return t, nil
+}
+
+// regexp builds the expression that matches the template and the placeholder of each of its groups
+func (u URLTemplate) regexp() (re *regexp.Regexp, groups []string, err error) {
+	var expr strings.Builder
+	last := 0
+	for _, loc := range placeholders.FindAllStringSubmatchIndex(u.Template, -1) {
+		expr.WriteString(regexp.QuoteMeta(u.Template[last:loc[0]]))
+		name := u.Template[loc[2]:loc[3]]
+		switch name {
+		case "s":
+			expr.WriteString(`[^/]*`)
+		case "q":
+			expr.WriteString(`([0-3]*)`)
+			groups = append(groups, name)
+		default:
+			expr.WriteString(`(\d+)`)
+			groups = append(groups, name)
+		}
+		last = loc[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(u.Template[last:]))
+	expr.WriteString("$")
+	has := make(map[string]bool)
+	for _, name := range groups {
+		has[name] = true
+	}
+	if !has["q"] && !(has["z"] && has["x"] && (has["y"] || has["-y"])) {
+		return nil, nil, errors.New("Template " + u.Template + " needs {q} or {z}, {x} and {y} or {-y} to parse tiles")
+	}
+	re, err = regexp.Compile(expr.String())
+	return
+}
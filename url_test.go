@@ -0,0 +1,78 @@
+package tiles
+
+import (
+	"testing"
+)
+
+func TestTileTMS(t *testing.T) {
+	tileTests := []struct {
+		tile, tms Tile
+	}{
+		{Tile{0, 0, 0}, Tile{0, 0, 0}},
+		{Tile{0, 0, 1}, Tile{0, 1, 1}},
+		{Tile{26, 48, 7}, Tile{26, 79, 7}},
+	}
+	errf := "Tile%+v.TMS() -> %+v"
+	for _, test := range tileTests {
+		if tms := test.tile.TMS(); tms != test.tms {
+			t.Errorf(errf, test.tile, tms)
+		}
+		if tile := FromTMS(test.tms.X, test.tms.Y, test.tms.Z); tile != test.tile {
+			t.Errorf("FromTMS(%+v) -> %+v", test.tms, tile)
+		}
+	}
+}
+
+func TestURLTemplateExpand(t *testing.T) {
+	tile := Tile{26, 48, 7}
+	urlTests := []struct {
+		tmpl URLTemplate
+		url  string
+	}{
+		{URLTemplate{Template: "/{z}/{x}/{y}.png"}, "/7/26/48.png"},
+		{URLTemplate{Template: "/tms/{z}/{x}/{-y}.png"}, "/tms/7/26/79.png"},
+		{URLTemplate{Template: "https://t.example.com/{q}.jpeg"}, "https://t.example.com/0231010.jpeg"},
+		{URLTemplate{Template: "https://{s}.example.com/{z}/{x}/{y}", Subdomains: []string{"a", "b", "c"}}, "https://c.example.com/7/26/48"},
+	}
+	errf := "URLTemplate%+v.Expand(%+v) -> %q"
+	for _, test := range urlTests {
+		if url, err := test.tmpl.Expand(tile); err != nil || url != test.url {
+			t.Errorf(errf, test.tmpl, tile, url)
+		}
+		if parsed, err := test.tmpl.Parse(test.url); err != nil || parsed != tile {
+			t.Errorf("URLTemplate%+v.Parse(%q) -> %+v, %v", test.tmpl, test.url, parsed, err)
+		}
+	}
+	for _, bad := range []Tile{{Z: ZMax + 1}, {X: 128, Y: 48, Z: 7}, {X: -1, Z: 1}} {
+		if url, err := urlTests[2].tmpl.Expand(bad); err == nil {
+			t.Errorf(errf, urlTests[2].tmpl, bad, url)
+		}
+	}
+}
+
+func TestURLTemplateParse(t *testing.T) {
+	urlTests := []struct {
+		tmpl string
+		url  string
+		tile Tile
+		err  bool
+	}{
+		{"/{z}/{x}/{y}.png", "https://example.com/tiles/7/26/48.png", Tile{26, 48, 7}, false},
+		{"/{z}/{x}/{y}.png", "/7/26/48.jpeg", Tile{}, true},
+		{"/{z}/{x}/{y}.png", "/7/128/48.png", Tile{}, true},
+		{"/{z}/{x}/{y}.png", "/99/0/0.png", Tile{}, true},
+		{"/{z}/{x}/{y}.png", "/7/-1/48.png", Tile{}, true},
+		{"/{q}", "/0231410", Tile{}, true},
+		{"/{q}/{z}", "/0231010/7", Tile{26, 48, 7}, false},
+		{"/{q}/{z}", "/0231010/6", Tile{}, true},
+		{"/{z}/{x}/{x}", "/7/26/27", Tile{}, true},
+		{"/{z}/{x}", "/7/26", Tile{}, true},
+	}
+	errf := "URLTemplate(%q).Parse(%q) -> %+v, %v"
+	for _, test := range urlTests {
+		tile, err := URLTemplate{Template: test.tmpl}.Parse(test.url)
+		if (err != nil) != test.err || tile != test.tile {
+			t.Errorf(errf, test.tmpl, test.url, tile, err)
+		}
+	}
+}
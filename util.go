@@ -12,9 +12,14 @@ const (
 	MinLon       float64 = -180
 	MaxLon       float64 = 180
 	EarthRadiusM float64 = 6378137
+	// EarthMeanRadiusM is the mean radius used for spherical distances
+	EarthMeanRadiusM float64 = 6371008.8
+	// EarthFlattening is the WGS84 ellipsoid flattening used for Vincenty distances
+	EarthFlattening float64 = 1 / 298.257223563
 )
 
 // TileSize is the size in pixels of each tile. It can be tuned at the package level.
+// Changing it is not safe while other goroutines are converting, use a Grid for per-call tile sizes.
 var TileSize = 256
 
 // ZMax is the maximum Z coordinate for a tile as well as quadkey level
@@ -32,28 +37,39 @@ func clip(val, min, max float64) float64 {
 // Gets the size of the x, y dimensions in pixels at the given zoom level
 // x == y since the map is a square
 func mapDimensions(zoom int) int {
-	//TODO panic outside of zoom bounds
-	return TileSize << uint(zoom)
+	return defaultGrid().MapDimensions(zoom)
 }
 
-// Gets the ground resoultion (meters/pixel) of the map at the lat and zoom
-// TODO remove if unused
-/*
-func grndRes(lat float64, zoom int) float64 {
-	lat = clip(lat, MinLat, MaxLat)
-	dim := float64(mapDimensions(zoom))
-	return math.Cos(lat*math.Pi/180) * 2 * math.Pi * EarthRadiusM / dim
+// ValidateZoom returns an error if zoom is outside of [0, ZMax].
+// Conversions that take a zoom don't validate it, so untrusted zooms should be validated first.
+func ValidateZoom(zoom int) error {
+	if zoom < 0 || zoom > ZMax {
+		return fmt.Errorf("Invalid zoom %d: must be in [0, %d]", zoom, ZMax)
+	}
+	return nil
+}
+
+// GroundResolution gets the ground resolution (meters/pixel) of the map at the lat and zoom
+func GroundResolution(lat float64, zoom int) float64 {
+	return defaultGrid().GroundResolution(lat, zoom)
+}
+
+// MapScale gets the map scale at the lat, zoom & screen DPI expressed as the denominator N of the ratio 1 : N.
+func MapScale(lat float64, zoom, dpi int) float64 {
+	return defaultGrid().MapScale(lat, zoom, dpi)
+}
+
+// ZoomForResolution gets the lowest zoom whose ground resolution at the lat is at least as fine as metersPerPixel.
+// The zoom is clipped to [0, ZMax]
+func ZoomForResolution(lat, metersPerPixel float64) int {
+	return defaultGrid().ZoomForResolution(lat, metersPerPixel)
 }
-*/
 
-// Gets the map scale at the lat, zoom & screen DPI expressed as the denominator N of the ratio 1 : N.
-// TODO remove if unused
-/*
-func mapScale(lat float64, zoom, dpi int) float64 {
-	d := float64(dpi)
-	return grndRes(lat, zoom) * d / 0.0254
+// ZoomForScale gets the lowest zoom whose map scale at the lat & screen DPI is at least as detailed as 1 : scale.
+// The zoom is clipped to [0, ZMax]
+func ZoomForScale(lat, scale float64, dpi int) int {
+	return defaultGrid().ZoomForScale(lat, scale, dpi)
 }
-*/
 
 // method for approx float equality
 func floatEquals(a, b float64) bool {
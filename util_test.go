@@ -38,14 +38,12 @@ func TestMapDimensions(t *testing.T) {
 	}
 }
 
-/*
-//TODO assert neither of these are used and remove
-func TestGroundRes(t *testing.T) {
+func TestGroundResolution(t *testing.T) {
 	lat := 40.0
 	var zoom int = 7
 	res := 936.86657226219847
-	if out := grndRes(lat, zoom); !floatEquals(out, res) {
-		t.Errorf("grndRes(%v, %v) -> %v not %v", lat, zoom, res, out)
+	if out := GroundResolution(lat, zoom); !floatEquals(out, res) {
+		t.Errorf("GroundResolution(%v, %v) -> %v not %v", lat, zoom, out, res)
 	}
 }
 
@@ -54,8 +52,59 @@ func TestMapScale(t *testing.T) {
 	var zoom int = 7
 	var dpi int = 96
 	scale := 3540913.0290224836
-	if out := mapScale(lat, zoom, dpi); !floatEquals(out, scale) {
-		t.Errorf("mapScale(%v, %v, %v) -> %v not %v", lat, zoom, dpi, scale, out)
+	if out := MapScale(lat, zoom, dpi); !floatEquals(out, scale) {
+		t.Errorf("MapScale(%v, %v, %v) -> %v not %v", lat, zoom, dpi, out, scale)
+	}
+}
+
+func TestZoomForResolution(t *testing.T) {
+	zoomTests := []struct {
+		lat, res float64
+		zoom     int
+	}{
+		{40, 936.86657226219847, 7},
+		{40, 936, 8},
+		{40, 1000, 7},
+		{0, 1e9, 0},
+		{0, 1e-9, ZMax},
+	}
+	errf := "ZoomForResolution(%v, %v) -> %v"
+	for _, test := range zoomTests {
+		if zoom := ZoomForResolution(test.lat, test.res); zoom != test.zoom {
+			t.Errorf(errf, test.lat, test.res, zoom)
+		}
+	}
+}
+
+func TestZoomForScale(t *testing.T) {
+	lat := 40.0
+	var dpi int = 96
+	scale := 3540913.0290224836
+	if zoom := ZoomForScale(lat, scale, dpi); zoom != 7 {
+		t.Errorf("ZoomForScale(%v, %v, %v) -> %v not 7", lat, scale, dpi, zoom)
+	}
+}
+
+func TestValidateZoom(t *testing.T) {
+	for _, zoom := range []int{-1, ZMax + 1, 64} {
+		if err := ValidateZoom(zoom); err == nil {
+			t.Errorf("ValidateZoom(%d) should be an error", zoom)
+		}
+	}
+	for _, zoom := range []int{0, ZMax} {
+		if err := ValidateZoom(zoom); err != nil {
+			t.Errorf("ValidateZoom(%d) -> %v", zoom, err)
+		}
+	}
+	// conversions don't validate, so zooms past ZMax still convert
+	esb := Coordinate{Lat: 40.7484, Lon: -73.9857}
+	if tile := FromCoordinate(esb.Lat, esb.Lon, ZMax+1); tile.Z != ZMax+1 {
+		t.Errorf("FromCoordinate(%d) -> %v", ZMax+1, tile)
+	}
+	if px := esb.ToPixel(ZMax + 1); px.Z != ZMax+1 {
+		t.Errorf("Coordinate.ToPixel(%d) -> %v", ZMax+1, px)
+	}
+	if res := GroundResolution(esb.Lat, ZMax+1); res <= 0 {
+		t.Errorf("GroundResolution(%d) -> %v", ZMax+1, res)
 	}
 }
-*/
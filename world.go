@@ -0,0 +1,81 @@
+package tiles
+
+import (
+	"fmt"
+	"math"
+)
+
+// WorldPoint is a point in the mercator projection normalized to [0, 1] with a NW origin (0,0).
+// It is independent of zoom and TileSize, so it converts to and from coordinates without losing precision.
+type WorldPoint struct {
+	X, Y float64
+}
+
+// FloatPixel is a sub-pixel position at a possibly fractional zoom level with a NW origin (0,0) of the projection
+type FloatPixel struct {
+	X, Y, Z float64
+}
+
+// ToWorld projects the coord to a WorldPoint.
+// Latitudes beyond Min/MaxLat project outside of [0, 1] and the poles are infinite.
+func (c Coordinate) ToWorld() WorldPoint {
+	sinLat := math.Sin(c.Lat * math.Pi / 180.0)
+	return WorldPoint{
+		X: (c.Lon + 180) / 360.0,
+		Y: 0.5 - math.Log((1+sinLat)/(1-sinLat))/(4*math.Pi),
+	}
+}
+
+// ToFloatPixel gets the sub-pixel position of the coord at the zoom level, which may be fractional
+func (c Coordinate) ToFloatPixel(zoom float64) FloatPixel {
+	return c.ToWorld().ToFloatPixel(zoom)
+}
+
+// ToCoords converts to WGS84 coordinates without clipping
+func (w WorldPoint) ToCoords() Coordinate {
+	x := w.X - 0.5
+	y := 0.5 - w.Y
+	return Coordinate{
+		Lat: 90 - 360*math.Atan(math.Exp(-y*2*math.Pi))/math.Pi,
+		Lon: 360.0 * x,
+	}
+}
+
+// ToFloatPixel scales the point to a sub-pixel position at the zoom level, which may be fractional
+func (w WorldPoint) ToFloatPixel(zoom float64) FloatPixel {
+	return defaultGrid().WorldToFloatPixel(w, zoom)
+}
+
+// containingTile returns the tile at the zoom level that contains the point by flooring its tile coordinates, clipped to the map
+func (w WorldPoint) containingTile(zoom int) Tile {
+	n := float64(uint64(1) << uint(zoom))
+	return Tile{
+		X: int(clip(math.Floor(w.X*n), 0, n-1)),
+		Y: int(clip(math.Floor(w.Y*n), 0, n-1)),
+		Z: zoom,
+	}
+}
+
+// ToPixel gets the Pixel nearest to the point at the zoom level, clipped to the map
+func (w WorldPoint) ToPixel(zoom int) Pixel {
+	return defaultGrid().WorldToPixel(w, zoom)
+}
+
+func (w WorldPoint) String() string {
+	return fmt.Sprintf("(%v, %v)", w.X, w.Y)
+}
+
+// ToWorld normalizes the pixel to a WorldPoint
+func (p FloatPixel) ToWorld() WorldPoint {
+	return defaultGrid().FloatPixelToWorld(p)
+}
+
+// ToCoords converts to WGS84 coordinates without clipping
+func (p FloatPixel) ToCoords() Coordinate {
+	return p.ToWorld().ToCoords()
+}
+
+// ToFloat returns the sub-pixel position of the NW corner of this pixel
+func (p Pixel) ToFloat() FloatPixel {
+	return FloatPixel{X: p.floatX(), Y: p.floatY(), Z: float64(p.Z)}
+}
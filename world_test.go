@@ -0,0 +1,40 @@
+package tiles
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWorldPointRoundTrip(t *testing.T) {
+	coords := []Coordinate{
+		{0, 0},
+		{40.7484, -73.9857},
+		{-33.8568, 151.2153},
+		{MaxLat, MinLon},
+	}
+	errf := "Coordinate%+v.ToWorld().ToCoords() -> %+v"
+	for _, c := range coords {
+		if rt := c.ToWorld().ToCoords(); math.Abs(rt.Lat-c.Lat) > 1e-12 || math.Abs(rt.Lon-c.Lon) > 1e-12 {
+			t.Errorf(errf, c, rt)
+		}
+		if rt := c.ToFloatPixel(7.5).ToCoords(); math.Abs(rt.Lat-c.Lat) > 1e-12 || math.Abs(rt.Lon-c.Lon) > 1e-12 {
+			t.Errorf("Coordinate%+v.ToFloatPixel(7.5).ToCoords() -> %+v", c, rt)
+		}
+	}
+}
+
+func TestFloatPixel(t *testing.T) {
+	c := Coordinate{40.0, -105.0}
+	fp := c.ToFloatPixel(7)
+	if p := c.ToPixel(7); math.Abs(fp.X-p.floatX()) > 0.5 || math.Abs(fp.Y-p.floatY()) > 0.5 {
+		t.Errorf("Coordinate%+v.ToFloatPixel(7) %+v is not within half a pixel of %+v", c, fp, p)
+	}
+	half := c.ToFloatPixel(6.5)
+	if !floatEquals(half.X*math.Sqrt2, fp.X) || !floatEquals(half.Y*math.Sqrt2, fp.Y) {
+		t.Errorf("Coordinate%+v.ToFloatPixel(6.5) -> %+v", c, half)
+	}
+	p := Pixel{6827, 12405, 7}
+	if f := p.ToFloat(); f != (FloatPixel{6827, 12405, 7}) {
+		t.Errorf("Pixel%+v.ToFloat() -> %+v", p, f)
+	}
+}